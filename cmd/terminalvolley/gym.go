@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"terminalvolley/internal/ai"
+	"terminalvolley/internal/engine"
+	"terminalvolley/internal/gym"
+)
+
+// runGym implements "terminalvolley gym": a reinforcement-learning
+// environment served over a socket until interrupted.
+func runGym(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("gym", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:5555", "TCP address, or unix:<path> for a Unix socket")
+	mode := fs.String("mode", "1v1", "1v1 or 2v2")
+	physName := fs.String("physics", "", "physics preset from config/physics.json (default: the file's default)")
+	tickRate := fs.Int("tickrate", engine.DefaultTickRate, "physics ticks per second")
+	kinds := make([]string, 4)
+	for i := range kinds {
+		fs.StringVar(&kinds[i], fmt.Sprintf("p%d", i+1), "agent", fmt.Sprintf("who plays player %d: agent (the client's actions) or cpu", i+1))
+	}
+	cpuLevel := fs.String("cpu-level", "normal", "CPU skill: "+strings.Join(ai.Levels, ", "))
+	cpuStyle := fs.String("cpu-style", "allround", "CPU play style: "+strings.Join(ai.Styles(), ", "))
+	frameSkip := fs.Int("frame-skip", 1, "ticks each step holds the actions for")
+	maxTime := fs.Duration("max-time", 0, "game time after which an episode ends (0 = when the match is over)")
+	rewards := gym.DefaultRewards
+	fs.Float64Var(&rewards.Point, "point-reward", rewards.Point, "reward per point won, and minus per point lost")
+	fs.Float64Var(&rewards.Touch, "touch-reward", rewards.Touch, "reward per touch of the ball by the team")
+	rules := engine.DefaultRules()
+	addRuleFlags(fs, &rules)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *tickRate < engine.MinTickRate || *tickRate > engine.MaxTickRate {
+		return fmt.Errorf("-tickrate must be between %d and %d", engine.MinTickRate, engine.MaxTickRate)
+	}
+	size, ok := teamSizes[*mode]
+	if !ok {
+		return fmt.Errorf("-mode must be one of %s", strings.Join(modes, ", "))
+	}
+	phys, _, err := physicsPreset(*physName)
+	if err != nil {
+		return err
+	}
+	skill, pers, err := cpuProfile(*cpuLevel, *cpuStyle)
+	if err != nil {
+		return err
+	}
+
+	opts := []gym.Option{
+		gym.WithGame(func() *engine.Game {
+			return engine.New(arenaW, arenaH,
+				engine.WithTickRate(*tickRate),
+				engine.WithRules(rules),
+				engine.WithPhysics(phys),
+				engine.WithTeamSize(size),
+			)
+		}),
+		gym.WithRewards(rewards),
+		gym.WithFrameSkip(*frameSkip),
+		gym.WithMaxTicks(int(maxTime.Seconds() * float64(*tickRate))),
+	}
+	for i, kind := range kinds[:2*size] {
+		switch kind {
+		case "agent":
+		case "cpu":
+			opts = append(opts, gym.WithController(i, func(seed int64) engine.Controller {
+				return ai.NewCPU(ai.WithDifficulty(skill), ai.WithPersonality(pers), ai.WithSeed(seed))
+			}))
+		default:
+			return fmt.Errorf("-p%d: unknown player %q (want agent or cpu)", i+1, kind)
+		}
+	}
+
+	network, addr := listenAddr(*listen)
+	if network == "unix" {
+		removeStaleSocket(addr)
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+	// Close the listener on Ctrl+C so a Unix socket file is removed.
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		l.Close()
+	}()
+
+	fmt.Fprintf(out, "gym: serving %s on %s %s\n", *mode, network, l.Addr())
+	start := time.Now()
+	err = gym.Serve(l, func() *gym.Env { return gym.New(opts...) })
+	fmt.Fprintf(out, "gym: stopped after %s\n", time.Since(start).Round(time.Second))
+	return err
+}
+
+// listenAddr splits a -listen value into network and address.
+func listenAddr(s string) (network, addr string) {
+	if path, ok := strings.CutPrefix(s, "unix:"); ok {
+		return "unix", path
+	}
+	return "tcp", s
+}
+
+// removeStaleSocket removes a Unix socket left behind at path by a server
+// that did not shut down. A live socket or any other file stays.
+func removeStaleSocket(path string) {
+	fi, err := os.Lstat(path)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	if c, err := net.Dial("unix", path); err == nil {
+		c.Close()
+		return
+	}
+	os.Remove(path)
+}
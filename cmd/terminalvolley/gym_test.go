@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListenAddr(t *testing.T) {
+	if n, a := listenAddr("unix:/tmp/volley.sock"); n != "unix" || a != "/tmp/volley.sock" {
+		t.Fatalf("got %s %s", n, a)
+	}
+	if n, a := listenAddr("127.0.0.1:5555"); n != "tcp" || a != "127.0.0.1:5555" {
+		t.Fatalf("got %s %s", n, a)
+	}
+}
+
+func TestRunGym_RejectsUnknownPlayer(t *testing.T) {
+	t.Chdir("../..") // for config/physics.json
+	err := runGym([]string{"-p2", "robot"}, &strings.Builder{})
+	if err == nil || !strings.Contains(err.Error(), "-p2") {
+		t.Fatalf("expected an error naming -p2, got %v", err)
+	}
+}
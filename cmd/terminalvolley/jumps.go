@@ -0,0 +1,72 @@
+package main
+
+import (
+	"time"
+
+	"terminalvolley/internal/engine"
+	"terminalvolley/internal/input"
+)
+
+// jumpHold makes jumps press-only on terminals that report no key
+// releases. There a key only counts as let go once its auto-repeat stops,
+// far too late to tell a tap from a hold, so cutting the jump at that
+// inferred release would cut it at an arbitrary point of the rise.
+// Instead a jump key stays held until the jump has peaked, and every jump
+// reaches full height.
+type jumpHold struct {
+	until   map[input.Key]time.Time
+	pending []input.Event
+}
+
+// rise is how long a jump takes to peak with p.
+func rise(p engine.Physics) time.Duration {
+	if p.Gravity <= 0 || p.JumpVelocity >= 0 {
+		return 0
+	}
+	return time.Duration(-p.JumpVelocity / p.Gravity * float64(time.Second))
+}
+
+// press notes that jump key k was pressed at now. A release of k still
+// held back is returned to be applied first, so the new press counts.
+func (j *jumpHold) press(k input.Key, now time.Time, p engine.Physics) []input.Event {
+	if j.until == nil {
+		j.until = make(map[input.Key]time.Time)
+	}
+	j.until[k] = now.Add(rise(p))
+	var out []input.Event
+	kept := j.pending[:0]
+	for _, ev := range j.pending {
+		if ev.Key == k {
+			out = append(out, ev)
+		} else {
+			kept = append(kept, ev)
+		}
+	}
+	j.pending = kept
+	return out
+}
+
+// release holds back the release ev of a jump key until its jump has
+// peaked and reports whether it did.
+func (j *jumpHold) release(ev input.Event, now time.Time) bool {
+	if !now.Before(j.until[ev.Key]) {
+		return false
+	}
+	j.pending = append(j.pending, ev)
+	return true
+}
+
+// due returns the held-back releases whose jump has peaked by now.
+func (j *jumpHold) due(now time.Time) []input.Event {
+	var out []input.Event
+	kept := j.pending[:0]
+	for _, ev := range j.pending {
+		if now.Before(j.until[ev.Key]) {
+			kept = append(kept, ev)
+		} else {
+			out = append(out, ev)
+		}
+	}
+	j.pending = kept
+	return out
+}
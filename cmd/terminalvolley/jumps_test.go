@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"terminalvolley/internal/engine"
+	"terminalvolley/internal/input"
+)
+
+func TestJumpHold_KeepsJumpUntilPeak(t *testing.T) {
+	phys := engine.DefaultPhysics()
+	peak := rise(phys)
+	if peak <= input.DefaultRepeatDelay {
+		t.Fatalf("expected the default rise (%v) to outlast the repeat delay", peak)
+	}
+	var j jumpHold
+	t0 := time.Now()
+	up := input.Event{Key: 'W', Type: input.KeyRelease}
+	if got := j.press('W', t0, phys); len(got) != 0 {
+		t.Fatalf("a first press released %+v", got)
+	}
+	if !j.release(up, t0.Add(input.DefaultRepeatDelay)) {
+		t.Fatalf("expected the inferred release to wait for the peak")
+	}
+	if got := j.due(t0.Add(peak - time.Millisecond)); len(got) != 0 {
+		t.Fatalf("released before the peak: %+v", got)
+	}
+	if got := j.due(t0.Add(peak)); len(got) != 1 || got[0] != up {
+		t.Fatalf("expected the release at the peak, got %+v", got)
+	}
+	if j.release(up, t0.Add(2*peak)) {
+		t.Fatalf("a release after the peak should pass at once")
+	}
+}
+
+func TestJumpHold_NewPressReleasesFirst(t *testing.T) {
+	phys := engine.DefaultPhysics()
+	var j jumpHold
+	t0 := time.Now()
+	up := input.Event{Key: 'W', Type: input.KeyRelease}
+	j.press('W', t0, phys)
+	j.release(up, t0.Add(input.DefaultRepeatDelay))
+	if got := j.press('W', t0.Add(input.DefaultRepeatDelay+time.Millisecond), phys); len(got) != 1 || got[0] != up {
+		t.Fatalf("expected the held-back release before the new press, got %+v", got)
+	}
+	if got := j.due(t0.Add(time.Minute)); len(got) != 0 {
+		t.Fatalf("the release went out twice: %+v", got)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import "time"
+
+// stepper is a fixed-timestep accumulator: real elapsed time is banked and
+// paid out in whole simulation ticks, so physics runs at a constant rate no
+// matter how often frames are drawn.
+type stepper struct {
+	tick time.Duration
+	acc  time.Duration
+
+	// maxSteps bounds the ticks run for one frame so a stall (suspended
+	// process, slow terminal) does not snowball into a burst of catch-up.
+	maxSteps int
+}
+
+func newStepper(tickRate int) *stepper {
+	return &stepper{
+		tick:     time.Second / time.Duration(tickRate),
+		maxSteps: tickRate / 4,
+	}
+}
+
+// Advance banks elapsed and returns how many ticks to simulate now, plus
+// how far (0..1) the leftover time reaches into the next tick.
+func (s *stepper) Advance(elapsed time.Duration) (steps int, alpha float64) {
+	s.acc += elapsed
+	steps = int(s.acc / s.tick)
+	if s.maxSteps > 0 && steps > s.maxSteps {
+		steps = s.maxSteps
+		s.acc = 0
+	} else {
+		s.acc -= time.Duration(steps) * s.tick
+	}
+	return steps, float64(s.acc) / float64(s.tick)
+}
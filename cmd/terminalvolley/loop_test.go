@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStepper_PaysOutWholeTicks(t *testing.T) {
+	s := newStepper(100) // 10ms ticks
+
+	steps, alpha := s.Advance(25 * time.Millisecond)
+	if steps != 2 {
+		t.Fatalf("expected 2 steps, got %d", steps)
+	}
+	if alpha < 0.49 || alpha > 0.51 {
+		t.Fatalf("expected alpha 0.5, got %v", alpha)
+	}
+
+	steps, alpha = s.Advance(5 * time.Millisecond)
+	if steps != 1 || alpha != 0 {
+		t.Fatalf("expected carried half tick to complete one step, got %d alpha %v", steps, alpha)
+	}
+}
+
+func TestStepper_ShortFramesAccumulate(t *testing.T) {
+	s := newStepper(60)
+	total := 0
+	for i := 0; i < 240; i++ {
+		steps, _ := s.Advance(time.Second / 240)
+		total += steps
+	}
+	if total < 59 || total > 60 {
+		t.Fatalf("expected ~60 steps over one second, got %d", total)
+	}
+}
+
+func TestStepper_CapsCatchUp(t *testing.T) {
+	s := newStepper(200)
+	steps, alpha := s.Advance(5 * time.Second)
+	if steps != s.maxSteps {
+		t.Fatalf("expected catch-up capped at %d, got %d", s.maxSteps, steps)
+	}
+	if alpha != 0 {
+		t.Fatalf("expected backlog dropped after cap, alpha=%v", alpha)
+	}
+}
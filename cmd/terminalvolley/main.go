@@ -2,31 +2,33 @@ package main
 
 import (
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
-	"math"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"terminalvolley/config"
+	"terminalvolley/internal/ai"
+	"terminalvolley/internal/engine"
 	"terminalvolley/internal/input"
+	"terminalvolley/internal/netplay"
 	"terminalvolley/internal/render"
 )
 
-func keyFromConfig(field, s string) (byte, error) {
-	if len(s) != 1 {
-		return 0, fmt.Errorf("%s must be exactly 1 ASCII character, got %q", field, s)
-	}
-	b := s[0]
-	if b > 127 {
-		return 0, fmt.Errorf("%s must be ASCII, got %q", field, s)
+func keyFromConfig(field, s string) (input.Key, error) {
+	k, err := input.ParseKey(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", field, err)
 	}
-	return b, nil
+	return k, nil
 }
 
-func mustKeyFromConfig(field, s string) byte {
+func mustKeyFromConfig(field, s string) input.Key {
 	b, err := keyFromConfig(field, s)
 	if err != nil {
 		log.Fatal(err)
@@ -34,26 +36,142 @@ func mustKeyFromConfig(field, s string) byte {
 	return b
 }
 
-func resetServe(w int, toLeft bool) (bx, by, vx, vy float64) {
-	// Serve from the middle to make the first hit easier.
-	bx = float64(w) * 0.5
-	by = 4
+// modes are the match formats by name, with the blobs per team.
+var (
+	modes     = []string{"1v1", "2v2"}
+	teamSizes = map[string]int{"1v1": 1, "2v2": 2}
+)
+
+// physicsPath is where the physics presets are loaded from and saved to.
+const physicsPath = "config/physics.json"
 
-	// Slow, playable serve speed (cells/sec).
-	vx = 6.0
-	if toLeft {
-		vx = -vx
+// physicsPreset loads the named preset from physicsPath, or the file's
+// default for "", and returns it with its name.
+func physicsPreset(name string) (engine.Physics, string, error) {
+	cfg, err := config.LoadPhysics(physicsPath)
+	if err != nil {
+		return engine.Physics{}, "", fmt.Errorf("load physics: %w", err)
+	}
+	if name == "" {
+		name = cfg.Default
+	}
+	p, err := cfg.Preset(name)
+	if err != nil {
+		return engine.Physics{}, "", fmt.Errorf("-physics: %w", err)
 	}
-	vy = 0
-	return
+	return p, name, nil
+}
+
+// addRuleFlags defines the match rule flags on fs, defaulting to rules.
+func addRuleFlags(fs *flag.FlagSet, rules *engine.Rules) {
+	fs.IntVar(&rules.PointsToWin, "points", rules.PointsToWin, "points that win a set (0 plays forever)")
+	fs.BoolVar(&rules.WinByTwo, "win-by-two", rules.WinByTwo, "a set needs a two-point lead")
+	fs.IntVar(&rules.Sets, "sets", rules.Sets, "best-of-N sets")
+	fs.BoolVar(&rules.SwitchSides, "switch-sides", rules.SwitchSides, "swap halves between sets")
+	fs.IntVar(&rules.MaxTouches, "touches", rules.MaxTouches, "touches allowed per side before the ball must cross the net (0 = unlimited, 3 = classic)")
+	fs.BoolVar(&rules.SideOut, "side-out", rules.SideOut, "only the serving side scores")
+}
+
+// binding maps a key to an engine action for one player.
+type binding struct {
+	player int
+	action engine.Action
+}
+
+// arenaW and arenaH are the arena size in cells.
+const (
+	arenaW = 80
+	arenaH = 24
+)
+
+// subcommands run instead of the game when named as the first argument.
+var subcommands = map[string]func(args []string, out io.Writer) error{
+	"sim": runSim,
+	"gym": runGym,
 }
 
 func main() {
-	const (
-		w   = 80
-		h   = 24
-		fps = 200
-	)
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:], os.Stdout); err != nil {
+				if !errors.Is(err, flag.ErrHelp) {
+					fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+				}
+				os.Exit(2)
+			}
+			return
+		}
+	}
+	// "host" and "join" play the game with a second person over TCP.
+	role, args := "", os.Args[1:]
+	if len(args) > 0 && (args[0] == "host" || args[0] == "join") {
+		role, args = args[0], args[1:]
+	}
+
+	kitty := flag.Bool("kitty", false, "use the kitty keyboard protocol for real key releases if the terminal supports it")
+	tickRate := flag.Int("tickrate", engine.DefaultTickRate, "physics ticks per second")
+	fps := flag.Int("fps", 60, "frames drawn per second")
+	interpolate := flag.Bool("interpolate", true, "blend blob and ball positions between physics ticks when drawing")
+	gfx := flag.String("gfx", "auto", "graphics: auto, ascii or halfblock (smooth, needs color and UTF-8)")
+	physName := flag.String("physics", "", "physics preset from config/physics.json (default: the file's default)")
+	showMenu := flag.Bool("menu", true, "start on the menu; Escape returns to it")
+	mode := flag.String("mode", "1v1", "1v1 or 2v2 (players 3 and 4 join as teammates)")
+	kinds := make([]string, 4)
+	for i := range kinds {
+		flag.StringVar(&kinds[i], fmt.Sprintf("p%d", i+1), "human", fmt.Sprintf("who plays player %d: human, cpu or bot:<command>", i+1))
+	}
+	cpuLevel := flag.String("cpu-level", "normal", "CPU skill: "+strings.Join(ai.Levels, ", "))
+	cpuStyle := flag.String("cpu-style", "allround", "CPU play style: "+strings.Join(ai.Styles(), ", "))
+	netcode := flag.String("netcode", netplay.NetcodeRollback, "host: rollback (both machines simulate) or snapshots (the host streams the match)")
+	inputDelay := flag.Duration("input-delay", 10*time.Millisecond, "host and join with rollback: how long local input waits before it takes effect")
+	rules := engine.DefaultRules()
+	addRuleFlags(flag.CommandLine, &rules)
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "usage: %[1]s [flags]\n       %[1]s host [flags] [listen address]\n       %[1]s join [flags] <host address>\n       %[1]s sim|gym [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.CommandLine.Parse(args)
+	if *tickRate < engine.MinTickRate || *tickRate > engine.MaxTickRate {
+		fmt.Fprintf(os.Stderr, "-tickrate must be between %d and %d\n", engine.MinTickRate, engine.MaxTickRate)
+		return
+	}
+	if *fps <= 0 {
+		fmt.Fprintln(os.Stderr, "-fps must be positive")
+		return
+	}
+	if _, ok := teamSizes[*mode]; !ok {
+		fmt.Fprintf(os.Stderr, "-mode must be one of %s\n", strings.Join(modes, ", "))
+		return
+	}
+
+	var online *onlineMatch
+	if role != "" {
+		var err error
+		switch {
+		case role == "host" && flag.NArg() <= 1:
+			online, err = hostMatch(flag.Arg(0), *netcode, *inputDelay)
+		case role == "join" && flag.NArg() == 1:
+			online, err = joinMatch(flag.Arg(0), *inputDelay)
+		default:
+			flag.Usage()
+			os.Exit(2)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		defer online.Close()
+		online.remoteKinds(kinds)
+		*showMenu = false
+	}
+
+	ctrls, err := newControllers(kinds, *cpuLevel, *cpuStyle, time.Now().UnixNano())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	defer func() { closeControllers(ctrls) }()
 
 	controlsCfg, err := config.LoadControls("config/controls.json")
 	if err != nil {
@@ -61,25 +179,98 @@ func main() {
 		return
 	}
 
-	// Map config controls (strings) to bytes.
-	// Existing config uses single-character strings; keep same assumption.
+	physCfg, err := config.LoadPhysics(physicsPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "load physics:", err)
+		return
+	}
+	if *physName == "" {
+		*physName = physCfg.Default
+	}
+	if _, err := physCfg.Preset(*physName); err != nil {
+		fmt.Fprintln(os.Stderr, "-physics:", err)
+		return
+	}
+
+	// Map config controls (characters or key names like "ArrowLeft") to keys.
 	quitKey := mustKeyFromConfig("controls.quit", controlsCfg.Quit)
 	serveLeftKey := mustKeyFromConfig("controls.serveLeft", controlsCfg.ServeLeft)
 	serveRightKey := mustKeyFromConfig("controls.serveRight", controlsCfg.ServeRight)
+	devKey := input.KeyUnknown
+	if controlsCfg.DevOverlay != "" {
+		devKey = mustKeyFromConfig("controls.devOverlay", controlsCfg.DevOverlay)
+	}
+
+	bindings := map[input.Key]binding{
+		mustKeyFromConfig("controls.player1.left", controlsCfg.Player1.Left):   {engine.Player1, engine.ActionLeft},
+		mustKeyFromConfig("controls.player1.right", controlsCfg.Player1.Right): {engine.Player1, engine.ActionRight},
+		mustKeyFromConfig("controls.player1.jump", controlsCfg.Player1.Jump):   {engine.Player1, engine.ActionJump},
+		mustKeyFromConfig("controls.player2.left", controlsCfg.Player2.Left):   {engine.Player2, engine.ActionLeft},
+		mustKeyFromConfig("controls.player2.right", controlsCfg.Player2.Right): {engine.Player2, engine.ActionRight},
+		mustKeyFromConfig("controls.player2.jump", controlsCfg.Player2.Jump):   {engine.Player2, engine.ActionJump},
+		serveLeftKey:  {engine.Player1, engine.ActionServe},
+		serveRightKey: {engine.Player2, engine.ActionServe},
+	}
+	// Players 3 and 4 are optional; older controls files lack them.
+	for i, pc := range []config.PlayerControls{controlsCfg.Player3, controlsCfg.Player4} {
+		player := engine.Player3 + i
+		field := fmt.Sprintf("controls.player%d", player+1)
+		for _, k := range []struct {
+			name, key string
+			action    engine.Action
+		}{
+			{"left", pc.Left, engine.ActionLeft},
+			{"right", pc.Right, engine.ActionRight},
+			{"jump", pc.Jump, engine.ActionJump},
+		} {
+			if k.key != "" {
+				bindings[mustKeyFromConfig(field+"."+k.name, k.key)] = binding{player, k.action}
+			}
+		}
+	}
+	newGame := func(preset, mode string) *engine.Game {
+		phys, _ := physCfg.Preset(preset)
+		return engine.New(arenaW, arenaH,
+			engine.WithTickRate(*tickRate),
+			engine.WithRules(rules),
+			engine.WithPhysics(phys),
+			engine.WithTeamSize(teamSizes[mode]),
+		)
+	}
+	g := newGame(*physName, *mode)
+	if online != nil {
+		g = online.start(g)
+	}
 
-	gameControls := Controls{
-		P1Left:     mustKeyFromConfig("controls.player1.left", controlsCfg.Player1.Left),
-		P1Right:    mustKeyFromConfig("controls.player1.right", controlsCfg.Player1.Right),
-		P1Jump:     mustKeyFromConfig("controls.player1.jump", controlsCfg.Player1.Jump),
-		P2Left:     mustKeyFromConfig("controls.player2.left", controlsCfg.Player2.Left),
-		P2Right:    mustKeyFromConfig("controls.player2.right", controlsCfg.Player2.Right),
-		P2Jump:     mustKeyFromConfig("controls.player2.jump", controlsCfg.Player2.Jump),
-		ServeLeft:  serveLeftKey,
-		ServeRight: serveRightKey,
+	var m menu
+	m.add("Mode", modes, *mode)
+	m.add("Physics", physCfg.Names(), *physName)
+	for i, kind := range kinds {
+		m.add(fmt.Sprintf("Player %d", i+1), kindChoices(kind), kind)
 	}
-	g := NewGame(w, h, fps, gameControls)
+	m.add("CPU level", ai.Levels, *cpuLevel)
+	m.add("CPU style", ai.Styles(), *cpuStyle)
+	inMenu := *showMenu
+	var tune tuner
+	menuHint := fmt.Sprintf("Enter = start, %s = quit", quitKey)
+	serveHint := fmt.Sprintf("(%s = P1 serve, %s = P2 serve)", serveLeftKey, serveRightKey)
 
-	r := render.NewRenderer(os.Stdout, w, h)
+	colorMode := render.DetectColorMode(os.Getenv)
+	sc := scene{
+		theme:       render.DefaultTheme(),
+		serveHint:   serveHint,
+		rematchHint: fmt.Sprintf("%s or %s = rematch, %s = quit", serveLeftKey, serveRightKey, quitKey),
+	}
+	if online != nil {
+		sc.serveHint = fmt.Sprintf("(%s or %s = serve)", serveLeftKey, serveRightKey)
+	}
+	if online != nil && online.guest != nil {
+		sc.rematchHint = fmt.Sprintf("the host starts a rematch, %s = quit", quitKey)
+	}
+	if sc.gfx, err = chooseGfx(*gfx, colorMode, os.Getenv); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
 
 	term, err := input.MakeRaw()
 	if err != nil {
@@ -88,79 +279,248 @@ func main() {
 	}
 	defer func() { _ = term.Restore() }()
 
-	keys := input.StartKeyReader()
+	// The renderer matches the terminal; the arena is centered in it.
+	screenW, screenH := arenaW, arenaH
+	resize := func() {
+		if tw, th, err := term.Size(); err == nil && tw > 0 && th > 0 {
+			screenW, screenH = tw, th
+		}
+	}
+	resize()
+	r := render.NewRenderer(os.Stdout, screenW, screenH)
+	r.SetColorMode(colorMode)
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+
+	// With the kitty protocol the terminal reports releases itself;
+	// otherwise they are inferred from the auto-repeat stream.
+	keyUps := false
+	var typed []byte
+	if *kitty {
+		keyUps, typed, err = term.EnableKittyKeyboard(200 * time.Millisecond)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "kitty keyboard:", err)
+		}
+	}
+
+	keys := input.StartKeyReader(typed)
 
 	// Ensure terminal is restored on Ctrl+C.
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigCh
-		_ = term.Restore()
-		_ = r.ShowCursor()
-		fmt.Fprint(os.Stdout, "\x1b[0m\x1b[2J\x1b[H")
-		os.Exit(130)
-	}()
 
 	if err := r.HideCursor(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 	}
 
-	ticker := time.NewTicker(time.Second / fps)
+	keyState := input.NewKeyState()
+	if controlsCfg.KeyRepeat.DelayMs > 0 {
+		keyState.RepeatDelay = time.Duration(controlsCfg.KeyRepeat.DelayMs) * time.Millisecond
+	}
+	if controlsCfg.KeyRepeat.TimeoutMs > 0 {
+		keyState.RepeatTimeout = time.Duration(controlsCfg.KeyRepeat.TimeoutMs) * time.Millisecond
+	}
+	// Without key releases a jump cannot be cut short, so jumps are
+	// press-only and always reach full height.
+	var jumps jumpHold
+	jumpKey := func(k input.Key) bool {
+		b, ok := bindings[k]
+		return ok && b.action == engine.ActionJump
+	}
+	apply := func(ev input.Event) {
+		b, ok := bindings[ev.Key]
+		if !ok {
+			return
+		}
+		if online != nil && online.apply(g, ctrls, b, ev) {
+			return
+		}
+		switch ev.Type {
+		case input.KeyPress:
+			if g.Over() && b.action == engine.ActionServe {
+				g.Reset()
+				return
+			}
+			if isHuman(ctrls, b.player) {
+				g.Press(b.player, b.action)
+			}
+		case input.KeyRelease:
+			if isHuman(ctrls, b.player) {
+				g.Release(b.player, b.action)
+			}
+		}
+	}
+
+	// Physics runs at a fixed tick rate; frames are drawn at fps and show
+	// the state blended between the last two ticks.
+	steps := newStepper(*tickRate)
+	prev, cur := snapshot(g), snapshot(g)
+
+	ticker := time.NewTicker(time.Second / time.Duration(*fps))
 	defer ticker.Stop()
 
-	for range ticker.C {
+	last := time.Now()
+	var left error
+frames:
+	for now := range ticker.C {
+		select {
+		case <-sigCh:
+			_ = term.Restore()
+			_ = r.Reset()
+			closeControllers(ctrls)
+			os.Exit(130)
+		case <-winch:
+			resize()
+			r.Resize(screenW, screenH)
+		default:
+		}
+		if online != nil {
+			if left = online.poll(g, ctrls); left != nil {
+				break frames
+			}
+		}
+
 		// Drain keys available this frame.
 		for {
 			select {
-			case k, ok := <-keys:
+			case ev, ok := <-keys:
 				if !ok {
+					_ = r.Reset()
 					return
 				}
 
 				// Normalize to upper for comparisons (matches previous behavior).
-				kU := k
-				if kU >= 'a' && kU <= 'z' {
-					kU = kU - 32
-				}
+				ev.Key = ev.Key.Upper()
 
-				if byte(kU) == quitKey {
-					_ = r.ShowCursor()
+				if ev.Key == quitKey && ev.Type == input.KeyPress {
+					_ = r.Reset()
 					return
 				}
+				// Ctrl+L repaints the whole screen, as in most TUIs.
+				if ev.Key == 'L' && ev.Mods&input.ModCtrl != 0 {
+					if ev.Type == input.KeyPress {
+						r.Invalidate()
+					}
+					continue
+				}
 
-				g.PressKey(byte(kU))
+				if inMenu {
+					if ev.Type != input.KeyRelease && m.handle(ev.Key) == menuStart {
+						for i := range kinds {
+							kinds[i] = m.value(fmt.Sprintf("Player %d", i+1))
+						}
+						// A bot that fails to start keeps the menu open.
+						started, err := newControllers(kinds, m.value("CPU level"), m.value("CPU style"), time.Now().UnixNano())
+						if err != nil {
+							m.status = err.Error()
+							continue
+						}
+						closeControllers(ctrls)
+						ctrls, m.status = started, ""
+						g = newGame(m.value("Physics"), m.value("Mode"))
+						prev, cur = snapshot(g), snapshot(g)
+						inMenu = false
+					}
+					continue
+				}
+				if devKey != input.KeyUnknown && ev.Key == devKey && online == nil {
+					if ev.Type == input.KeyPress {
+						tune.open = !tune.open
+						tune.status = ""
+					}
+					continue
+				}
+				// The open overlay takes every key but releases, so keys
+				// held before it opened do not get stuck.
+				if tune.open && ev.Type != input.KeyRelease {
+					phys := g.Physics()
+					switch tune.handle(ev, &phys) {
+					case tunerChanged:
+						tune.status = ""
+						if err := g.SetPhysics(phys); err != nil {
+							tune.status = err.Error()
+						}
+					case tunerSave:
+						physCfg.Set(tunedPreset, g.Physics())
+						tune.status = fmt.Sprintf("saved as preset %q", tunedPreset)
+						if err := config.SavePhysics(physicsPath, physCfg); err != nil {
+							tune.status = "save: " + err.Error()
+						}
+						m.setChoices("Physics", physCfg.Names())
+					}
+					continue
+				}
+				if _, bound := bindings[ev.Key]; ev.Key == input.KeyEscape && !bound && online == nil {
+					if ev.Type == input.KeyPress {
+						inMenu = true
+					}
+					continue
+				}
+
+				if keyUps {
+					apply(ev)
+					continue
+				}
+				if ev.Type == input.KeyPress {
+					for _, ev := range keyState.Observe(ev.Key, now) {
+						if jumpKey(ev.Key) {
+							for _, up := range jumps.press(ev.Key, now, g.Physics()) {
+								apply(up)
+							}
+						}
+						apply(ev)
+					}
+				}
 
 			default:
 				goto keysDone
 			}
 		}
 	keysDone:
+		for _, ev := range keyState.Expire(now) {
+			if jumpKey(ev.Key) && jumps.release(ev, now) {
+				continue
+			}
+			apply(ev)
+		}
+		for _, ev := range jumps.due(now) {
+			apply(ev)
+		}
 
-		g.Step()
+		n, alpha := steps.Advance(now.Sub(last))
+		last = now
+		if inMenu || online != nil && online.paused() {
+			n = 0
+		}
+		for i := 0; i < n; i++ {
+			if online == nil || !online.step(g, ctrls) {
+				control(g, ctrls)
+				g.Step()
+			}
+			prev, cur = cur, snapshot(g)
+		}
+		if online != nil {
+			online.sync(g, n, now)
+		}
 
 		// ---- Render ----
-		frame := render.NewFrame(w, h)
-		frame.Clear(' ')
-		frame.DrawGround()
-		frame.DrawNet()
-
-		// Draw players/ball from the (package main) Game state.
-		frame.DrawBlob(int(math.Round(g.p1x)), int(math.Round(g.p1y)))
-		frame.DrawBlob(int(math.Round(g.p2x)), int(math.Round(g.p2y)))
-		frame.DrawBall(int(math.Round(g.bx)), int(math.Round(g.by)))
-
-		// Top row UI.
-		p1Score, p2Score := g.Score()
-		score := fmt.Sprintf("P1 %d : %d P2", p1Score, p2Score)
-		for i := 0; i < len(score) && i < w; i++ {
-			frame.Set(i, 0, score[i])
-		}
-		if g.WaitingServe() {
-			msg := fmt.Sprintf("  (%c = serve left, %c = serve right)", serveLeftKey, serveRightKey)
-			for i := 0; i < len(msg) && i+len(score) < w; i++ {
-				frame.Set(len(score)+i, 0, msg[i])
-			}
+		v := cur
+		if online != nil && online.guest != nil {
+			v = online.view(now, *interpolate)
+		} else if *interpolate {
+			v = lerpView(prev, cur, alpha)
+		}
+		arena := sc.drawArena(g, v)
+		if online != nil && online.waiting() {
+			drawBanner(arena, []string{"Waiting for a guest on " + online.listener.Addr().String(), fmt.Sprintf("%s = quit", quitKey)}, sc.theme.Text)
 		}
+		if inMenu {
+			arena = m.draw(arenaW, arenaH, sc.theme, menuHint)
+		} else if tune.open {
+			tune.draw(arena, g.Physics(), sc.theme.Text)
+		}
+		frame := fitScreen(arena, screenW, screenH)
 
 		if err := r.Draw(frame); err != nil && !errors.Is(err, syscall.EPIPE) {
 			fmt.Fprintln(os.Stderr, err)
@@ -168,5 +528,9 @@ func main() {
 		}
 	}
 
-	_ = r.ShowCursor()
+	_ = r.Reset()
+	if left != nil {
+		_ = term.Restore()
+		fmt.Fprintln(os.Stderr, left)
+	}
 }
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"terminalvolley/internal/input"
+)
+
+func TestKeyFromConfig_SingleASCII(t *testing.T) {
+	b, err := keyFromConfig("controls.quit", "Q")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b != 'Q' {
+		t.Fatalf("got %q want %q", b, 'Q')
+	}
+}
+
+func TestKeyFromConfig_NamedKey(t *testing.T) {
+	k, err := keyFromConfig("controls.player2.left", "ArrowLeft")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if k != input.KeyArrowLeft {
+		t.Fatalf("got %v want %v", k, input.KeyArrowLeft)
+	}
+}
+
+func TestKeyFromConfig_RejectsInvalid(t *testing.T) {
+	for _, s := range []string{"", "AB", "Arrow"} {
+		if _, err := keyFromConfig("controls.quit", s); err == nil {
+			t.Fatalf("expected error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+
+	"terminalvolley/internal/input"
+	"terminalvolley/internal/render"
+)
+
+// menuItem is a setting on the start menu, cycled through its choices.
+type menuItem struct {
+	label   string
+	choices []string
+	sel     int
+}
+
+// menu is the start screen: Up/Down pick a setting, Left/Right change it
+// and Enter starts the match.
+type menu struct {
+	items []menuItem
+	cur   int
+	// status reports why the match did not start.
+	status string
+}
+
+// menuAction is what a key press on the menu asks the game to do.
+type menuAction int
+
+const (
+	menuNone menuAction = iota
+	menuStart
+)
+
+// add appends a setting with the initial choice selected.
+func (m *menu) add(label string, choices []string, initial string) {
+	it := menuItem{label: label, choices: choices}
+	for i, c := range choices {
+		if c == initial {
+			it.sel = i
+		}
+	}
+	m.items = append(m.items, it)
+}
+
+// setChoices replaces the choices of the setting called label and keeps
+// its selection if it is still offered.
+func (m *menu) setChoices(label string, choices []string) {
+	for i := range m.items {
+		it := &m.items[i]
+		if it.label != label {
+			continue
+		}
+		cur := it.choices[it.sel]
+		it.choices, it.sel = choices, 0
+		for j, c := range choices {
+			if c == cur {
+				it.sel = j
+			}
+		}
+	}
+}
+
+// value returns the selected choice of the setting called label.
+func (m *menu) value(label string) string {
+	for _, it := range m.items {
+		if it.label == label {
+			return it.choices[it.sel]
+		}
+	}
+	return ""
+}
+
+func (m *menu) handle(k input.Key) menuAction {
+	switch k {
+	case input.KeyArrowUp:
+		m.cur = (m.cur + len(m.items) - 1) % len(m.items)
+	case input.KeyArrowDown:
+		m.cur = (m.cur + 1) % len(m.items)
+	case input.KeyArrowLeft, input.KeyArrowRight:
+		it := &m.items[m.cur]
+		d := 1
+		if k == input.KeyArrowLeft {
+			d = len(it.choices) - 1
+		}
+		it.sel = (it.sel + d) % len(it.choices)
+	case input.KeyEnter, input.KeySpace:
+		return menuStart
+	}
+	return menuNone
+}
+
+// draw renders the menu on a w x h frame.
+func (m *menu) draw(w, h int, theme render.Theme, hint string) *render.Frame {
+	frame := render.NewFrame(w, h)
+	frame.Fill(' ', theme.Sky)
+
+	lines := []string{"T E R M I N A L   V O L L E Y", ""}
+	for i, it := range m.items {
+		marker := "  "
+		if i == m.cur {
+			marker = "> "
+		}
+		lines = append(lines, fmt.Sprintf("%s%-10s < %-10s >", marker, it.label, it.choices[it.sel]))
+	}
+	lines = append(lines, "", "Up/Down choose, Left/Right change", hint)
+	if m.status != "" {
+		lines = append(lines, "", m.status)
+	}
+	drawBanner(frame, lines, theme.Text)
+	return frame
+}
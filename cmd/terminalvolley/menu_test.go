@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"terminalvolley/internal/input"
+	"terminalvolley/internal/render"
+)
+
+func TestMenu_NavigateAndCycle(t *testing.T) {
+	var m menu
+	m.add("Physics", []string{"arcade", "classic", "moon"}, "classic")
+	m.add("Mode", []string{"1v1", "2v2"}, "1v1")
+
+	if got := m.value("Physics"); got != "classic" {
+		t.Fatalf("expected initial choice, got %q", got)
+	}
+	m.handle(input.KeyArrowLeft)
+	m.handle(input.KeyArrowLeft)
+	if got := m.value("Physics"); got != "moon" {
+		t.Fatalf("expected left to wrap around, got %q", got)
+	}
+
+	m.handle(input.KeyArrowUp) // wraps to the last row
+	m.handle(input.KeyArrowRight)
+	if got := m.value("Mode"); got != "2v2" {
+		t.Fatalf("expected the second setting to change, got %q", got)
+	}
+	if got := m.handle(input.KeyEnter); got != menuStart {
+		t.Fatalf("expected Enter to start, got %v", got)
+	}
+}
+
+func TestMenu_DrawMarksCurrentRow(t *testing.T) {
+	var m menu
+	m.add("Physics", []string{"classic", "moon"}, "moon")
+	f := m.draw(80, 24, render.DefaultTheme(), "Enter = start")
+
+	var all strings.Builder
+	for y := 0; y < f.Height; y++ {
+		all.WriteString(f.Row(y) + "\n")
+	}
+	if !strings.Contains(all.String(), "> Physics    < moon       >") {
+		t.Fatalf("expected selected physics row, got\n%s", all.String())
+	}
+	if !strings.Contains(all.String(), "Enter = start") {
+		t.Fatalf("expected hint on the menu")
+	}
+}
+
+func TestMenu_DrawShowsStatus(t *testing.T) {
+	var m menu
+	m.add("Player 1", []string{"human", "bot:x"}, "bot:x")
+	m.status = "player 1: bot x: not found"
+	f := m.draw(80, 24, render.DefaultTheme(), "Enter = start")
+
+	var all strings.Builder
+	for y := 0; y < f.Height; y++ {
+		all.WriteString(f.Row(y) + "\n")
+	}
+	if !strings.Contains(all.String(), m.status) {
+		t.Fatalf("expected the status on the menu, got\n%s", all.String())
+	}
+}
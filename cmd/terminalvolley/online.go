@@ -0,0 +1,346 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"terminalvolley/internal/engine"
+	"terminalvolley/internal/input"
+	"terminalvolley/internal/netplay"
+	"terminalvolley/internal/rollback"
+)
+
+// joinTimeout bounds how long "join" waits for the host to answer.
+const joinTimeout = 5 * time.Second
+
+// onlineMatch is the network side of a hosted or joined match. The host's
+// match flags decide it. With snapshots the guest plays player 2 and
+// mirrors the host's game; with rollback each machine plays one team and
+// the other team's input comes over the network.
+type onlineMatch struct {
+	welcome    netplay.Welcome
+	inputDelay time.Duration
+
+	// A host listens until the guest has joined, which arrives on joined.
+	listener net.Listener
+	joined   chan net.Conn
+
+	host  *netplay.Host  // hosting with snapshots
+	guest *netplay.Guest // joined with snapshots
+	conn  net.Conn       // either end with rollback
+	link  *rollback.Stream
+
+	session *rollback.Session
+	// pads and local are the keys held by this machine's players, fed to
+	// the session each tick.
+	pads  []pad
+	local []engine.Input
+
+	smooth smoother
+}
+
+// hostMatch listens on addr for a guest to play with netcode.
+func hostMatch(addr, netcode string, inputDelay time.Duration) (*onlineMatch, error) {
+	if netcode != netplay.NetcodeRollback && netcode != netplay.NetcodeSnapshots {
+		return nil, fmt.Errorf("-netcode must be %s or %s", netplay.NetcodeRollback, netplay.NetcodeSnapshots)
+	}
+	l, err := netplay.Listen(addr)
+	if err != nil {
+		return nil, fmt.Errorf("host: %w", err)
+	}
+	o := newOnlineMatch(inputDelay)
+	o.welcome.Netcode = netcode
+	o.listener, o.joined = l, make(chan net.Conn, 1)
+	return o, nil
+}
+
+// joinMatch joins the host at addr.
+func joinMatch(addr string, inputDelay time.Duration) (*onlineMatch, error) {
+	conn, w, err := netplay.JoinConn(addr, joinTimeout)
+	if err != nil {
+		return nil, err
+	}
+	o := newOnlineMatch(inputDelay)
+	o.welcome = w
+	switch w.Netcode {
+	case netplay.NetcodeRollback:
+		o.conn = conn
+	case netplay.NetcodeSnapshots:
+		o.guest = netplay.NewGuest(conn, w)
+	default:
+		conn.Close()
+		return nil, fmt.Errorf("join: host plays with %s netcode", w.Netcode)
+	}
+	return o, nil
+}
+
+func newOnlineMatch(inputDelay time.Duration) *onlineMatch {
+	return &onlineMatch{
+		inputDelay: inputDelay,
+		pads:       make([]pad, 2*engine.MaxTeamSize),
+		local:      make([]engine.Input, 2*engine.MaxTeamSize),
+	}
+}
+
+// hosting reports whether this machine hosts the match.
+func (o *onlineMatch) hosting() bool { return o.listener != nil }
+
+// remoteKinds makes the players the other machine plays keyboard players.
+func (o *onlineMatch) remoteKinds(kinds []string) {
+	switch {
+	case o.hosting() && o.welcome.Netcode == netplay.NetcodeRollback:
+		remoteKeyboards(kinds, engine.Team2)
+	case o.hosting():
+		kinds[engine.Player2] = "human"
+	case o.guest == nil:
+		remoteKeyboards(kinds, 1-o.welcome.Player%2)
+	}
+}
+
+// start returns the game to play, which is g for the host and a replica
+// of the host's game for a guest. The host starts waiting for the guest.
+func (o *onlineMatch) start(g *engine.Game) *engine.Game {
+	if o.hosting() {
+		netcode := o.welcome.Netcode
+		o.welcome = netplay.NewWelcome(g, engine.Player2)
+		o.welcome.Netcode = netcode
+		go o.accept()
+		return g
+	}
+	g = o.welcome.NewGame()
+	if o.guest != nil {
+		o.smooth.push(snapshot(g), 0, time.Now())
+	} else {
+		o.startSession(g, g.Player(o.welcome.Player).Team)
+	}
+	return g
+}
+
+// accept hands the first guest that was welcomed to joined.
+func (o *onlineMatch) accept() {
+	for {
+		conn, err := netplay.AcceptConn(o.listener, o.welcome)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err == nil {
+			o.joined <- conn
+			return
+		}
+	}
+}
+
+func (o *onlineMatch) startSession(g *engine.Game, team int) {
+	o.link = rollback.NewStream(o.conn)
+	o.session = rollback.New(g, team, o.link, rollback.WithInputDelay(delayTicks(o.inputDelay, o.welcome.TickRate)))
+}
+
+// paused reports whether the game must not be stepped here: the host is
+// still waiting for its guest, or the host steps it for a snapshot guest.
+func (o *onlineMatch) paused() bool { return o.waiting() || o.guest != nil }
+
+// waiting reports whether the host is still waiting for its guest.
+func (o *onlineMatch) waiting() bool {
+	return o.hosting() && o.host == nil && o.session == nil
+}
+
+// poll starts the match once the guest has joined and returns why it
+// ended if the other player has left or the session failed.
+func (o *onlineMatch) poll(g *engine.Game, ctrls []engine.Controller) error {
+	var gone <-chan struct{}
+	switch {
+	case o.host != nil:
+		gone = o.host.Done()
+	case o.guest != nil:
+		gone = o.guest.Done()
+	case o.link != nil:
+		gone = o.link.Done()
+	}
+	select {
+	case conn := <-o.joined:
+		o.listener.Close()
+		if o.welcome.Netcode == netplay.NetcodeRollback {
+			o.conn = conn
+			o.startSession(g, engine.Team1)
+		} else {
+			o.host = netplay.NewHost(conn)
+			ctrls[engine.Player2] = o.host
+		}
+	case <-gone:
+		switch {
+		case o.host != nil && o.host.Err() != nil:
+			return o.host.Err()
+		case o.guest != nil && o.guest.Err() != nil:
+			return o.guest.Err()
+		case o.link != nil && o.link.Err() != nil:
+			return fmt.Errorf("the other player left: %w", o.link.Err())
+		}
+		return errors.New("the other player left")
+	default:
+	}
+	if o.session != nil {
+		return o.session.Err()
+	}
+	return nil
+}
+
+// apply routes the key event for binding b and reports whether it was
+// handled here. A guest's keys all steer its one player on the host. With
+// rollback either side's keys steer the local players; the session starts
+// rematches itself. Otherwise keys go to the game as offline.
+func (o *onlineMatch) apply(g *engine.Game, ctrls []engine.Controller, b binding, ev input.Event) bool {
+	switch {
+	case o.guest != nil:
+		// An auto-repeat changes nothing: the key is still held.
+		if ev.Type != input.KeyRepeat {
+			o.guest.Send(o.pads[0].apply(b.action, ev.Type == input.KeyPress))
+		}
+		return true
+	case o.session != nil:
+		if ev.Type == input.KeyRepeat {
+			return true
+		}
+		p := b.player / 2 * 2
+		if !o.session.Local(p) {
+			p++
+		}
+		if p < g.NumPlayers() && isHuman(ctrls, p) {
+			in := o.pads[p].apply(b.action, ev.Type == input.KeyPress)
+			in.Serve = in.Serve || o.local[p].Serve
+			o.local[p] = in
+		}
+		return true
+	}
+	return false
+}
+
+// step runs one tick of a rollback session and reports whether it did;
+// otherwise the game is stepped as offline.
+func (o *onlineMatch) step(g *engine.Game, ctrls []engine.Controller) bool {
+	if o.session == nil {
+		return false
+	}
+	for p, c := range ctrls {
+		if c != nil && p < g.NumPlayers() && o.session.Local(p) {
+			o.local[p] = c.Control(g, p)
+		}
+	}
+	if o.session.Step(o.local) {
+		for p := range o.local {
+			o.local[p].Serve = false
+		}
+	}
+	return true
+}
+
+// sync sends the game to a snapshot guest after n ticks were stepped, or
+// takes in the snapshots that arrived from the host.
+func (o *onlineMatch) sync(g *engine.Game, n int, now time.Time) {
+	if o.host != nil && n > 0 {
+		o.host.Send(g)
+	}
+	if o.guest == nil {
+		return
+	}
+	for len(o.guest.Snapshots()) > 0 {
+		s := <-o.guest.Snapshots()
+		from := g.Ticks()
+		if g.Restore(s) == nil {
+			o.smooth.push(snapshot(g), time.Duration(float64(s.Tick-from)*g.DT()*float64(time.Second)), now)
+		}
+	}
+}
+
+// view returns what a snapshot guest draws at now.
+func (o *onlineMatch) view(now time.Time, interpolate bool) view {
+	if !interpolate {
+		return o.smooth.cur
+	}
+	return o.smooth.view(now)
+}
+
+// Close hangs up and stops listening.
+func (o *onlineMatch) Close() {
+	if o.listener != nil {
+		o.listener.Close()
+	}
+	if o.host != nil {
+		o.host.Close()
+	}
+	if o.guest != nil {
+		o.guest.Close()
+	}
+	if o.conn != nil {
+		o.conn.Close()
+	}
+}
+
+// remoteKeyboards makes the players of team keyboard players, so that no
+// CPU or bot is started for players the other machine plays.
+func remoteKeyboards(kinds []string, team int) {
+	for i := team; i < len(kinds); i += 2 {
+		kinds[i] = "human"
+	}
+}
+
+// delayTicks converts an input delay to whole ticks at tickRate.
+func delayTicks(d time.Duration, tickRate int) int {
+	return int(math.Round(d.Seconds() * float64(tickRate)))
+}
+
+// pad tracks the keys held for a player online, whose input goes through
+// the network code instead of straight into a game.
+type pad struct {
+	held engine.Input
+}
+
+// apply presses or releases a and returns the input to send. A serve
+// press is a one-shot request on top of the held keys.
+func (p *pad) apply(a engine.Action, down bool) engine.Input {
+	switch a {
+	case engine.ActionLeft:
+		p.held.Left = down
+	case engine.ActionRight:
+		p.held.Right = down
+	case engine.ActionJump:
+		p.held.Jump = down
+	case engine.ActionServe:
+		in := p.held
+		in.Serve = down
+		return in
+	}
+	return p.held
+}
+
+// smoother blends what a guest draws between the last two snapshots from
+// the host, which arrive less often and less evenly than frames are
+// drawn. The view trails the host by about one snapshot.
+type smoother struct {
+	prev, cur view
+	// at is when cur arrived and span the game time between prev and cur.
+	at   time.Time
+	span time.Duration
+}
+
+// push makes v, received at now and span of game time after the previous
+// snapshot, the newest view. A span that is not positive, as after a
+// rematch, shows v at once.
+func (s *smoother) push(v view, span time.Duration, now time.Time) {
+	s.prev, s.cur = s.cur, v
+	s.at, s.span = now, span
+	if span <= 0 {
+		s.prev = v
+	}
+}
+
+// view returns the blended view at now.
+func (s *smoother) view(now time.Time) view {
+	t := 1.0
+	if s.span > 0 {
+		t = min(float64(now.Sub(s.at))/float64(s.span), 1)
+	}
+	return lerpView(s.prev, s.cur, t)
+}
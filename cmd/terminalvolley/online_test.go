@@ -0,0 +1,166 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"terminalvolley/internal/engine"
+	"terminalvolley/internal/input"
+	"terminalvolley/internal/netplay"
+	"terminalvolley/internal/rollback"
+)
+
+func TestPad_TracksHeldKeysAndServesOnce(t *testing.T) {
+	var p pad
+	if in := p.apply(engine.ActionLeft, true); in != (engine.Input{Left: true}) {
+		t.Fatalf("after pressing left got %+v", in)
+	}
+	if in := p.apply(engine.ActionJump, true); in != (engine.Input{Left: true, Jump: true}) {
+		t.Fatalf("after pressing jump got %+v", in)
+	}
+	if in := p.apply(engine.ActionServe, true); in != (engine.Input{Left: true, Jump: true, Serve: true}) {
+		t.Fatalf("serving got %+v", in)
+	}
+	if in := p.apply(engine.ActionServe, false); in.Serve {
+		t.Fatalf("releasing serve still serves: %+v", in)
+	}
+	if in := p.apply(engine.ActionLeft, false); in != (engine.Input{Jump: true}) {
+		t.Fatalf("after releasing left got %+v", in)
+	}
+}
+
+func ballAt(x float64) view {
+	return view{players: []engine.PlayerState{{X: 10}}, ball: engine.BallState{X: x, Y: 5}}
+}
+
+func TestSmoother_BlendsAcrossSnapshotSpan(t *testing.T) {
+	var s smoother
+	t0 := time.Now()
+	s.push(ballAt(10), 0, t0)
+	s.push(ballAt(12), 20*time.Millisecond, t0)
+
+	if x := s.view(t0).ball.X; x != 10 {
+		t.Fatalf("expected the view to start at the previous snapshot, got x=%v", x)
+	}
+	if x := s.view(t0.Add(10 * time.Millisecond)).ball.X; x < 10.99 || x > 11.01 {
+		t.Fatalf("expected halfway at half the span, got x=%v", x)
+	}
+	if x := s.view(t0.Add(time.Second)).ball.X; x != 12 {
+		t.Fatalf("expected a late snapshot to hold at the newest, got x=%v", x)
+	}
+}
+
+func TestSmoother_ShowsRewindAtOnce(t *testing.T) {
+	var s smoother
+	t0 := time.Now()
+	s.push(ballAt(10), 0, t0)
+	s.push(ballAt(12), 0, t0) // the tick went back: a rematch
+	if x := s.view(t0).ball.X; x != 12 {
+		t.Fatalf("expected the new match at once, got x=%v", x)
+	}
+}
+
+func TestDelayTicks(t *testing.T) {
+	for _, c := range []struct {
+		d    time.Duration
+		rate int
+		want int
+	}{
+		{10 * time.Millisecond, 200, 2},
+		{0, 200, 0},
+		{12 * time.Millisecond, 60, 1},
+		{40 * time.Millisecond, 60, 2},
+	} {
+		if got := delayTicks(c.d, c.rate); got != c.want {
+			t.Errorf("delayTicks(%v, %d) = %d, want %d", c.d, c.rate, got, c.want)
+		}
+	}
+}
+
+func TestRemoteKeyboards(t *testing.T) {
+	kinds := []string{"cpu", "bot:x", "cpu", "cpu"}
+	remoteKeyboards(kinds, engine.Team2)
+	want := []string{"cpu", "human", "cpu", "human"}
+	if !slices.Equal(kinds, want) {
+		t.Fatalf("kinds = %v, want %v", kinds, want)
+	}
+}
+
+func TestOnlineMatch_HostAndJoin(t *testing.T) {
+	for _, netcode := range []string{netplay.NetcodeRollback, netplay.NetcodeSnapshots} {
+		host, err := hostMatch("127.0.0.1:0", netcode, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer host.Close()
+		g := host.start(engine.New(80, 24, engine.WithTeamSize(2)))
+		if !host.waiting() || !host.paused() {
+			t.Fatalf("%s: expected the host to wait for its guest", netcode)
+		}
+
+		guest, err := joinMatch(host.listener.Addr().String(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replica := guest.start(engine.New(10, 10))
+		if replica.W != 80 || replica.NumPlayers() != 4 {
+			t.Fatalf("%s: expected a replica of the host's game, got %dx%d with %d players", netcode, replica.W, replica.H, replica.NumPlayers())
+		}
+		if paused := guest.guest != nil; guest.paused() != paused {
+			t.Fatalf("%s: a guest steps the game only with rollback", netcode)
+		}
+
+		ctrls := make([]engine.Controller, g.NumPlayers())
+		for deadline := time.Now().Add(time.Second); host.waiting(); time.Sleep(time.Millisecond) {
+			if err := host.poll(g, ctrls); err != nil || time.Now().After(deadline) {
+				t.Fatalf("%s: the guest never joined: %v", netcode, err)
+			}
+		}
+		switch netcode {
+		case netplay.NetcodeRollback:
+			if !host.session.Local(engine.Player3) || !guest.session.Local(engine.Player2) {
+				t.Fatalf("expected each machine to play one team")
+			}
+		case netplay.NetcodeSnapshots:
+			if ctrls[engine.Player2] != host.host {
+				t.Fatalf("expected the guest to control player 2 on the host")
+			}
+		}
+
+		guest.Close()
+		for deadline := time.Now().Add(time.Second); host.poll(g, ctrls) == nil; time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: the host did not notice the guest leaving", netcode)
+			}
+		}
+	}
+}
+
+func TestOnlineMatch_RollbackKeysSurviveAutoRepeat(t *testing.T) {
+	g := engine.New(80, 24)
+	o := newOnlineMatch(0)
+	o.session = rollback.New(g, engine.Team1, nil)
+	left := binding{engine.Player1, engine.ActionLeft}
+	serve := binding{engine.Player1, engine.ActionServe}
+
+	for _, step := range []struct {
+		b    binding
+		typ  input.EventType
+		want engine.Input
+	}{
+		{left, input.KeyPress, engine.Input{Left: true}},
+		{left, input.KeyRepeat, engine.Input{Left: true}},
+		{left, input.KeyRepeat, engine.Input{Left: true}},
+		{serve, input.KeyPress, engine.Input{Left: true, Serve: true}},
+		{serve, input.KeyRepeat, engine.Input{Left: true, Serve: true}},
+		{left, input.KeyRelease, engine.Input{Serve: true}},
+	} {
+		if !o.apply(g, nil, step.b, input.Event{Type: step.typ}) {
+			t.Fatalf("expected the session to take the key")
+		}
+		if got := o.local[engine.Player1]; got != step.want {
+			t.Fatalf("after %v of action %v got %+v, want %+v", step.typ, step.b.action, got, step.want)
+		}
+	}
+}
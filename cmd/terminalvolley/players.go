@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"terminalvolley/internal/ai"
+	"terminalvolley/internal/bot"
+	"terminalvolley/internal/engine"
+)
+
+// controllerKinds are who can play a blob: a person at the keyboard or
+// the computer. An external bot is "bot:" followed by its command line.
+var controllerKinds = []string{"human", "cpu"}
+
+const botPrefix = "bot:"
+
+// kindChoices returns the menu choices for a player whose flag said kind:
+// a bot command is offered next to the usual kinds.
+func kindChoices(kind string) []string {
+	if !strings.HasPrefix(kind, botPrefix) {
+		return controllerKinds
+	}
+	return append(append([]string(nil), controllerKinds...), kind)
+}
+
+// newControllers returns a controller per player for the given kinds;
+// human players get nil and are driven by key bindings. CPU players play
+// at the named level and style, each with its own seed derived from seed.
+// Bots are started here; closeControllers stops them.
+func newControllers(kinds []string, level, style string, seed int64) ([]engine.Controller, error) {
+	skill, pers, err := cpuProfile(level, style)
+	if err != nil {
+		return nil, err
+	}
+	ctrls := make([]engine.Controller, len(kinds))
+	for i, kind := range kinds {
+		c, err := newController(kind, skill, pers, seed+int64(i))
+		if err != nil {
+			closeControllers(ctrls)
+			return nil, fmt.Errorf("player %d: %w", i+1, err)
+		}
+		ctrls[i] = c
+	}
+	return ctrls, nil
+}
+
+// cpuProfile looks up a CPU level and style by name.
+func cpuProfile(level, style string) (ai.Difficulty, ai.Personality, error) {
+	skill, err := ai.Level(level)
+	if err != nil {
+		return ai.Difficulty{}, ai.Personality{}, err
+	}
+	pers, err := ai.Style(style)
+	if err != nil {
+		return ai.Difficulty{}, ai.Personality{}, err
+	}
+	return skill, pers, nil
+}
+
+// newController returns the controller for one player of the given kind,
+// nil for a human.
+func newController(kind string, skill ai.Difficulty, pers ai.Personality, seed int64, botOpts ...bot.Option) (engine.Controller, error) {
+	switch kind {
+	case "human":
+		return nil, nil
+	case "cpu":
+		return ai.NewCPU(ai.WithDifficulty(skill), ai.WithPersonality(pers), ai.WithSeed(seed)), nil
+	}
+	cmd := strings.Fields(strings.TrimPrefix(kind, botPrefix))
+	if !strings.HasPrefix(kind, botPrefix) || len(cmd) == 0 {
+		return nil, fmt.Errorf("unknown controller %q (want human, cpu or bot:<command>)", kind)
+	}
+	b, err := bot.Start(cmd[0], cmd[1:], botOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// closeControllers stops the controllers that run something, such as
+// bot processes.
+func closeControllers(ctrls []engine.Controller) {
+	for _, c := range ctrls {
+		if cl, ok := c.(io.Closer); ok {
+			cl.Close()
+		}
+	}
+}
+
+// control feeds every computer-controlled player its input for the next
+// tick.
+func control(g *engine.Game, ctrls []engine.Controller) {
+	for i, c := range ctrls {
+		if c != nil && i < g.NumPlayers() {
+			g.SetInput(i, c.Control(g, i))
+		}
+	}
+}
+
+// isHuman reports whether player i takes keyboard input.
+func isHuman(ctrls []engine.Controller, i int) bool {
+	return i >= len(ctrls) || ctrls[i] == nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"terminalvolley/internal/engine"
+)
+
+func TestNewControllers(t *testing.T) {
+	ctrls, err := newControllers([]string{"human", "cpu"}, "normal", "allround", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isHuman(ctrls, 0) || isHuman(ctrls, 1) || !isHuman(ctrls, 5) {
+		t.Fatalf("unexpected controllers %v", ctrls)
+	}
+	if _, err := newControllers([]string{"robot"}, "normal", "allround", 1); err == nil {
+		t.Fatalf("expected error for unknown controller")
+	}
+	if _, err := newControllers([]string{"human", "bot:"}, "normal", "allround", 1); err == nil {
+		t.Fatalf("expected error for a bot without a command")
+	}
+	if _, err := newControllers([]string{"bot:./no-such-bot"}, "normal", "allround", 1); err == nil {
+		t.Fatalf("expected error for a bot that cannot start")
+	}
+	if _, err := newControllers([]string{"cpu"}, "godlike", "allround", 1); err == nil {
+		t.Fatalf("expected error for unknown CPU level")
+	}
+	if _, err := newControllers([]string{"cpu"}, "normal", "libero", 1); err == nil {
+		t.Fatalf("expected error for unknown CPU style")
+	}
+}
+
+func TestControl_DrivesCPUPlayers(t *testing.T) {
+	g := engine.New(80, 24)
+	ctrls, _ := newControllers([]string{"cpu", "human"}, "normal", "allround", 1)
+	for i := 0; i < 50 && g.WaitingServe(); i++ {
+		control(g, ctrls)
+		g.Step()
+	}
+	if g.WaitingServe() {
+		t.Fatalf("expected the CPU to serve")
+	}
+	if g.Held(engine.Player2) != (engine.Input{}) {
+		t.Fatalf("expected the human player untouched")
+	}
+}
+
+func TestKindChoices(t *testing.T) {
+	if got := kindChoices("cpu"); len(got) != len(controllerKinds) {
+		t.Fatalf("unexpected choices %v", got)
+	}
+	got := kindChoices("bot:python3 bot.py")
+	if len(got) != len(controllerKinds)+1 || got[len(got)-1] != "bot:python3 bot.py" {
+		t.Fatalf("expected the bot offered on the menu, got %v", got)
+	}
+	if len(controllerKinds) != 2 {
+		t.Fatalf("kindChoices must not modify controllerKinds, got %v", controllerKinds)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"terminalvolley/internal/engine"
+	"terminalvolley/internal/render"
+)
+
+// view holds the positions drawn for one frame, which may lie between two
+// simulation ticks.
+type view struct {
+	players []engine.PlayerState
+	ball    engine.BallState
+}
+
+func snapshot(g *engine.Game) view {
+	v := view{ball: g.Ball()}
+	for i := 0; i < g.NumPlayers(); i++ {
+		v.players = append(v.players, g.Player(i))
+	}
+	return v
+}
+
+// maxLerpJump is the largest per-tick move that is blended; anything
+// further is a teleport (serve reset) and is drawn at its new position.
+const maxLerpJump = 4.0
+
+// lerpView blends positions from prev towards cur by t in [0, 1].
+func lerpView(prev, cur view, t float64) view {
+	out := view{players: append([]engine.PlayerState(nil), cur.players...), ball: cur.ball}
+	if len(prev.players) != len(cur.players) {
+		return out
+	}
+	for i := range out.players {
+		out.players[i].X, out.players[i].Y = lerpPoint(prev.players[i].X, prev.players[i].Y, cur.players[i].X, cur.players[i].Y, t)
+	}
+	out.ball.X, out.ball.Y = lerpPoint(prev.ball.X, prev.ball.Y, cur.ball.X, cur.ball.Y, t)
+	return out
+}
+
+func lerpPoint(x0, y0, x1, y1, t float64) (float64, float64) {
+	if math.Abs(x1-x0) > maxLerpJump || math.Abs(y1-y0) > maxLerpJump {
+		return x1, y1
+	}
+	return x0 + (x1-x0)*t, y0 + (y1-y0)*t
+}
+
+// gfxMode selects how blobs and ball are drawn.
+type gfxMode uint8
+
+const (
+	// gfxASCII draws sprites from plain characters and works everywhere.
+	gfxASCII gfxMode = iota
+	// gfxHalfBlock draws on a canvas of half-block pixels with twice the
+	// vertical resolution; it needs colors and a UTF-8 terminal.
+	gfxHalfBlock
+)
+
+// chooseGfx resolves the -gfx flag. "auto" picks half blocks when the
+// terminal has colors and the locale is UTF-8; asking for half blocks
+// without colors is an error.
+func chooseGfx(name string, mode render.ColorMode, getenv func(string) string) (gfxMode, error) {
+	switch name {
+	case "ascii":
+		return gfxASCII, nil
+	case "halfblock":
+		// Half blocks draw everything in colors; without them only stray
+		// blocks would remain.
+		if mode == render.ColorNone {
+			return gfxASCII, fmt.Errorf("graphics mode %q needs colors, which NO_COLOR or the terminal turned off", name)
+		}
+		return gfxHalfBlock, nil
+	case "auto":
+		if mode != render.ColorNone && utf8Locale(getenv) {
+			return gfxHalfBlock, nil
+		}
+		return gfxASCII, nil
+	}
+	return gfxASCII, fmt.Errorf("unknown graphics mode %q (want auto, ascii or halfblock)", name)
+}
+
+// utf8Locale reports whether the effective locale uses UTF-8.
+func utf8Locale(getenv func(string) string) bool {
+	for _, k := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := getenv(k); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	return false
+}
+
+// scene draws matches with a fixed look.
+type scene struct {
+	theme render.Theme
+	gfx   gfxMode
+	// serveHint is shown next to the score while a serve is pending.
+	serveHint string
+	// rematchHint is shown under the winner announcement.
+	rematchHint string
+}
+
+// drawArena renders the match into a frame the size of the game arena,
+// with blobs and ball taken from v.
+func (s scene) drawArena(g *engine.Game, v view) *render.Frame {
+	frame := render.NewFrame(g.W, g.H)
+	frame.Fill(' ', s.theme.Sky)
+
+	if s.gfx == gfxHalfBlock {
+		s.drawSprites(frame, g, v)
+	} else {
+		frame.DrawNet(s.theme.Net)
+		for _, p := range v.players {
+			frame.DrawBlob(int(math.Round(p.X)), int(math.Round(p.Y)), s.theme.Blobs[p.Team])
+		}
+		frame.DrawBall(int(math.Round(v.ball.X)), int(math.Round(v.ball.Y)), s.theme.Ball)
+	}
+	frame.DrawGround(s.theme.Sand)
+
+	// Top row UI.
+	score := scoreLine(g)
+	frame.DrawText(0, 0, score, s.theme.Text)
+	if g.WaitingServe() && !g.Over() {
+		frame.DrawText(len(score), 0, "  "+s.serveHint, s.theme.Text)
+	}
+	if g.Rules().MaxTouches > 0 && !g.WaitingServe() {
+		drawTouches(frame, g, s.theme.Text)
+	}
+
+	if g.Over() {
+		s1, s2 := g.Sets()
+		lines := []string{fmt.Sprintf("%s wins!", teamName(g, g.Winner()))}
+		if g.Rules().Sets > 1 {
+			lines = append(lines, fmt.Sprintf("Sets %d : %d", s1, s2))
+		}
+		lines = append(lines, "", s.rematchHint)
+		drawBanner(frame, lines, s.theme.Text)
+	}
+	return frame
+}
+
+// teamName names team t: the player in a duel, the team otherwise.
+func teamName(g *engine.Game, t int) string {
+	if g.TeamSize() == 1 {
+		return fmt.Sprintf("Player %d", t+1)
+	}
+	return fmt.Sprintf("Team %d", t+1)
+}
+
+// scoreLine labels the score with the player or team on each half, so it
+// still reads left to right after a side switch.
+func scoreLine(g *engine.Game) string {
+	var pts [2]int
+	pts[engine.Team1], pts[engine.Team2] = g.Score()
+	left, right := engine.Team1, engine.Team2
+	if g.Player(engine.Player1).Side == engine.SideRight {
+		left, right = right, left
+	}
+	label := "P"
+	if g.TeamSize() > 1 {
+		label = "T"
+	}
+	line := fmt.Sprintf("%s%d %d : %d %s%d", label, left+1, pts[left], pts[right], label, right+1)
+	if g.Rules().Sets > 1 {
+		var sets [2]int
+		sets[engine.Team1], sets[engine.Team2] = g.Sets()
+		line += fmt.Sprintf("  sets %d:%d", sets[left], sets[right])
+	}
+	return line
+}
+
+// drawTouches shows each side's touch count under the score, at the outer
+// edge of its half.
+func drawTouches(frame *render.Frame, g *engine.Game, st render.Style) {
+	limit := g.Rules().MaxTouches
+	left := fmt.Sprintf("touches %d/%d", g.Touches(engine.SideLeft), limit)
+	right := fmt.Sprintf("touches %d/%d", g.Touches(engine.SideRight), limit)
+	frame.DrawText(1, 1, left, st)
+	frame.DrawText(frame.Width-1-len(right), 1, right, st)
+}
+
+// drawBanner writes lines centered in the frame.
+func drawBanner(frame *render.Frame, lines []string, st render.Style) {
+	y := (frame.Height - len(lines)) / 2
+	for i, line := range lines {
+		x := (frame.Width - len([]rune(line))) / 2
+		if x < 0 {
+			x = 0
+		}
+		frame.DrawText(x, y+i, line, st)
+	}
+}
+
+// drawSprites paints net, blobs and ball as half-block pixels using the
+// engine's collision shapes, so what you see is what the ball bounces off.
+func (s scene) drawSprites(frame *render.Frame, g *engine.Game, v view) {
+	phys := g.Physics()
+	canvas := render.NewCanvas(g.W, g.H-1)
+	canvas.Fill(s.theme.Sky.Bg)
+	canvas.FillColumn(g.NetX(), g.NetTop(), g.H-2, s.theme.Net.Fg)
+
+	for _, p := range v.players {
+		// The blob is the top of its collision circle, cut at its feet.
+		canvas.FillDisc(p.X, p.Y-0.5, phys.BlobRadius, p.Y+0.5, s.theme.Blobs[p.Team].Fg)
+	}
+	canvas.FillDisc(v.ball.X, v.ball.Y, phys.BallRadius, math.Inf(1), s.theme.Ball.Fg)
+
+	frame.DrawCanvas(canvas, 0, 0)
+}
+
+// fitScreen places arena in the middle of a width x height screen. When the
+// terminal is smaller than the arena it returns a notice instead.
+func fitScreen(arena *render.Frame, width, height int) *render.Frame {
+	screen := render.NewFrame(width, height)
+	if width < arena.Width || height < arena.Height {
+		drawBanner(screen, []string{
+			"Terminal too small",
+			fmt.Sprintf("need %dx%d, have %dx%d", arena.Width, arena.Height, width, height),
+		}, render.Style{Attr: render.AttrBold})
+		return screen
+	}
+
+	screen.Blit(arena, (width-arena.Width)/2, (height-arena.Height)/2)
+	return screen
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"terminalvolley/internal/engine"
+	"terminalvolley/internal/render"
+)
+
+func TestDrawArena_ScoreAndServeHint(t *testing.T) {
+	g := engine.New(80, 24)
+	sc := scene{theme: render.DefaultTheme(), gfx: gfxASCII, serveHint: "(S = serve)"}
+	f := sc.drawArena(g, snapshot(g))
+	if f.Width != 80 || f.Height != 24 {
+		t.Fatalf("unexpected arena size %dx%d", f.Width, f.Height)
+	}
+	if got := f.Row(0); !strings.HasPrefix(got, "P1 0 : 0 P2  (S = serve)") {
+		t.Fatalf("unexpected top row %q", got)
+	}
+
+	theme := render.DefaultTheme()
+	p := g.Player(engine.Player2)
+	if got := f.At(int(p.X), int(p.Y)).Style; got != theme.Blobs[engine.Player2] {
+		t.Fatalf("expected player 2 blob style, got %+v", got)
+	}
+	if got := f.At(1, f.Height-1).Style; got != theme.Sand {
+		t.Fatalf("expected sand style on ground row, got %+v", got)
+	}
+}
+
+func TestFitScreen_CentersArena(t *testing.T) {
+	arena := render.NewFrame(4, 2)
+	arena.Fill('#', render.Style{Bg: render.RGB(0, 0, 255)})
+
+	f := fitScreen(arena, 8, 6)
+	if f.Width != 8 || f.Height != 6 {
+		t.Fatalf("unexpected screen size %dx%d", f.Width, f.Height)
+	}
+	want := []string{"        ", "        ", "  ####  ", "  ####  ", "        ", "        "}
+	for y, row := range want {
+		if got := f.Row(y); got != row {
+			t.Fatalf("row %d: got %q want %q", y, got, row)
+		}
+	}
+	if got := f.At(2, 2).Style; got != arena.At(0, 0).Style {
+		t.Fatalf("expected arena style preserved, got %+v", got)
+	}
+	if got := f.At(0, 0).Style; got != (render.Style{}) {
+		t.Fatalf("expected default style outside arena, got %+v", got)
+	}
+}
+
+func TestFitScreen_TooSmall(t *testing.T) {
+	arena := render.NewFrame(80, 24)
+	f := fitScreen(arena, 40, 10)
+
+	var all strings.Builder
+	for y := 0; y < f.Height; y++ {
+		all.WriteString(f.Row(y))
+	}
+	if !strings.Contains(all.String(), "Terminal too small") {
+		t.Fatalf("expected too-small notice, got %q", all.String())
+	}
+	if !strings.Contains(all.String(), "need 80x24, have 40x10") {
+		t.Fatalf("expected size details, got %q", all.String())
+	}
+}
+
+func TestLerpView_BlendsAndSkipsTeleports(t *testing.T) {
+	prev := view{ball: engine.BallState{X: 10, Y: 10}, players: []engine.PlayerState{{X: 20, Y: 22}}}
+	cur := view{ball: engine.BallState{X: 12, Y: 11}, players: []engine.PlayerState{{X: 40, Y: 22}}}
+
+	got := lerpView(prev, cur, 0.5)
+	if got.ball.X != 11 || got.ball.Y != 10.5 {
+		t.Fatalf("expected ball blended to (11, 10.5), got (%v, %v)", got.ball.X, got.ball.Y)
+	}
+	if got.players[0].X != 40 {
+		t.Fatalf("expected teleport drawn at new position, got x=%v", got.players[0].X)
+	}
+}
+
+func TestDrawArena_HalfBlockUsesCanvasColors(t *testing.T) {
+	g := engine.New(80, 24)
+	theme := render.DefaultTheme()
+	sc := scene{theme: theme, gfx: gfxHalfBlock}
+	f := sc.drawArena(g, snapshot(g))
+
+	// The blob body sits on the ground row above the sand.
+	p := g.Player(engine.Player1)
+	c := f.At(int(p.X), int(p.Y))
+	if c.Style.Bg != theme.Blobs[engine.Player1].Fg {
+		t.Fatalf("expected blob color under player 1, got %+v", c)
+	}
+
+	var halfBlocks int
+	for _, c := range f.Cells {
+		if c.Ch == '▀' {
+			halfBlocks++
+		}
+	}
+	if halfBlocks == 0 {
+		t.Fatalf("expected half-block cells in halfblock mode")
+	}
+	if got := f.At(1, f.Height-1).Style; got != theme.Sand {
+		t.Fatalf("expected sand on ground row, got %+v", got)
+	}
+}
+
+func TestChooseGfx(t *testing.T) {
+	utf8 := func(k string) string {
+		if k == "LANG" {
+			return "en_US.UTF-8"
+		}
+		return ""
+	}
+	latin1 := func(k string) string {
+		if k == "LANG" {
+			return "de_DE.ISO-8859-1"
+		}
+		return ""
+	}
+
+	tests := []struct {
+		name   string
+		mode   render.ColorMode
+		getenv func(string) string
+		want   gfxMode
+	}{
+		{"auto", render.ColorTrue, utf8, gfxHalfBlock},
+		{"auto", render.ColorNone, utf8, gfxASCII},
+		{"auto", render.Color256, latin1, gfxASCII},
+		{"ascii", render.ColorTrue, utf8, gfxASCII},
+		{"halfblock", render.Color16, latin1, gfxHalfBlock},
+	}
+	for _, tt := range tests {
+		got, err := chooseGfx(tt.name, tt.mode, tt.getenv)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Fatalf("%s/%d: got %d want %d", tt.name, tt.mode, got, tt.want)
+		}
+	}
+
+	if _, err := chooseGfx("sixel", render.ColorTrue, utf8); err == nil {
+		t.Fatalf("expected error for unknown mode")
+	}
+	if _, err := chooseGfx("halfblock", render.ColorNone, utf8); err == nil {
+		t.Fatalf("expected error for half blocks without colors")
+	}
+}
+
+func TestDrawArena_WinnerBanner(t *testing.T) {
+	g := engine.New(80, 24, engine.WithRules(engine.Rules{PointsToWin: 1}))
+	// Toss and walk away so the ball lands on player 1's half.
+	g.Press(engine.Player1, engine.ActionServe)
+	g.Press(engine.Player1, engine.ActionLeft)
+	for i := 0; i < 2000 && !g.Over(); i++ {
+		g.Step()
+	}
+	if !g.Over() {
+		t.Fatalf("expected the served ball to decide a one-point match")
+	}
+
+	sc := scene{theme: render.DefaultTheme(), rematchHint: "S/K = rematch"}
+	f := sc.drawArena(g, snapshot(g))
+
+	var all strings.Builder
+	for y := 0; y < f.Height; y++ {
+		all.WriteString(f.Row(y))
+	}
+	want := fmt.Sprintf("Player %d wins!", g.Winner()+1)
+	if !strings.Contains(all.String(), want) {
+		t.Fatalf("expected %q on screen", want)
+	}
+	if !strings.Contains(all.String(), "S/K = rematch") {
+		t.Fatalf("expected rematch hint on screen")
+	}
+}
+
+func TestScoreLine_FollowsSidesAndSets(t *testing.T) {
+	g := engine.New(80, 24, engine.WithRules(engine.Rules{PointsToWin: 1, Sets: 3, SwitchSides: true}))
+	if got := scoreLine(g); got != "P1 0 : 0 P2  sets 0:0" {
+		t.Fatalf("unexpected score line %q", got)
+	}
+
+	// Let the first serve decide set one; afterwards sides are swapped.
+	g.Press(engine.Player1, engine.ActionServe)
+	g.Press(engine.Player1, engine.ActionLeft)
+	for i := 0; i < 2000; i++ {
+		if s1, s2 := g.Sets(); s1+s2 > 0 {
+			break
+		}
+		g.Step()
+	}
+	s1, s2 := g.Sets()
+	want := fmt.Sprintf("P2 0 : 0 P1  sets %d:%d", s2, s1)
+	if got := scoreLine(g); got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestDrawArena_TouchCounts(t *testing.T) {
+	g := engine.New(80, 24, engine.WithRules(engine.Rules{MaxTouches: 3}))
+	sc := scene{theme: render.DefaultTheme(), gfx: gfxASCII}
+	if got := sc.drawArena(g, snapshot(g)).Row(1); strings.Contains(got, "touches") {
+		t.Fatalf("expected no touch counts before the serve, got %q", got)
+	}
+
+	g.Press(engine.Player1, engine.ActionServe)
+	row := sc.drawArena(g, snapshot(g)).Row(1)
+	if !strings.HasPrefix(row, " touches 0/3") || !strings.HasSuffix(row, "touches 0/3 ") {
+		t.Fatalf("unexpected touch row %q", row)
+	}
+}
+
+func TestScoreLine_Teams(t *testing.T) {
+	g := engine.New(80, 24, engine.WithTeamSize(2))
+	if got := scoreLine(g); got != "T1 0 : 0 T2" {
+		t.Fatalf("unexpected score line %q", got)
+	}
+	if got := teamName(g, engine.Team2); got != "Team 2" {
+		t.Fatalf("unexpected team name %q", got)
+	}
+}
+
+func TestDrawArena_DoublesUseTeamColors(t *testing.T) {
+	g := engine.New(80, 24, engine.WithTeamSize(2))
+	theme := render.DefaultTheme()
+	sc := scene{theme: theme, gfx: gfxASCII}
+	f := sc.drawArena(g, snapshot(g))
+	for i := 0; i < g.NumPlayers(); i++ {
+		p := g.Player(i)
+		if got := f.At(int(p.X), int(p.Y)).Style; got != theme.Blobs[p.Team] {
+			t.Fatalf("player %d: expected team %d color, got %+v", i+1, p.Team+1, got)
+		}
+	}
+}
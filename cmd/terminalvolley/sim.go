@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"terminalvolley/internal/ai"
+	"terminalvolley/internal/bot"
+	"terminalvolley/internal/engine"
+	"terminalvolley/internal/sim"
+)
+
+// runSim implements "terminalvolley sim": matches between two contenders
+// with no terminal, reported to out.
+func runSim(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("sim", flag.ContinueOnError)
+	var kinds, levels, styles [2]string
+	for c, name := range []string{"a", "b"} {
+		fs.StringVar(&kinds[c], name, "cpu", fmt.Sprintf("contender %s: cpu or bot:<command>", strings.ToUpper(name)))
+		fs.StringVar(&levels[c], name+"-level", "normal", fmt.Sprintf("CPU skill of %s: %s", strings.ToUpper(name), strings.Join(ai.Levels, ", ")))
+		fs.StringVar(&styles[c], name+"-style", "allround", fmt.Sprintf("CPU play style of %s: %s", strings.ToUpper(name), strings.Join(ai.Styles(), ", ")))
+	}
+	matches := fs.Int("matches", 100, "matches to play; the contenders swap sides every match")
+	mode := fs.String("mode", "1v1", "1v1 or 2v2")
+	physName := fs.String("physics", "", "physics preset from config/physics.json (default: the file's default)")
+	tickRate := fs.Int("tickrate", engine.DefaultTickRate, "physics ticks per second")
+	seed := fs.Int64("seed", 1, "seed for the CPU players")
+	maxTime := fs.Duration("max-time", 30*time.Minute, "game time after which an undecided match is abandoned")
+	workers := fs.Int("workers", 0, "matches played at once (0 = one per CPU)")
+	botTimeout := fs.Duration("bot-timeout", time.Second, "how long a tick waits for a bot's reply")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	rules := engine.DefaultRules()
+	addRuleFlags(fs, &rules)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *matches <= 0 {
+		return fmt.Errorf("-matches must be positive")
+	}
+	if *tickRate < engine.MinTickRate || *tickRate > engine.MaxTickRate {
+		return fmt.Errorf("-tickrate must be between %d and %d", engine.MinTickRate, engine.MaxTickRate)
+	}
+	size, ok := teamSizes[*mode]
+	if !ok {
+		return fmt.Errorf("-mode must be one of %s", strings.Join(modes, ", "))
+	}
+	phys, preset, err := physicsPreset(*physName)
+	if err != nil {
+		return err
+	}
+
+	var contenders [2]sim.Contender
+	for c := range contenders {
+		kind := kinds[c]
+		if kind == "human" {
+			return fmt.Errorf("contender %c: sim needs cpu or bot:<command>", 'A'+c)
+		}
+		skill, pers, err := cpuProfile(levels[c], styles[c])
+		if err != nil {
+			return fmt.Errorf("contender %c: %w", 'A'+c, err)
+		}
+		name := kind
+		if kind == "cpu" {
+			name = fmt.Sprintf("cpu %s %s", levels[c], styles[c])
+		}
+		contenders[c] = sim.Contender{
+			Name: name,
+			New: func(m, i int) (engine.Controller, error) {
+				return newController(kind, skill, pers, *seed+int64(m*2*engine.MaxTeamSize+i), bot.WithTimeout(*botTimeout))
+			},
+		}
+	}
+
+	start := time.Now()
+	rep, err := sim.Run(sim.Config{
+		Contenders: contenders,
+		Matches:    *matches,
+		NewGame: func() *engine.Game {
+			return engine.New(arenaW, arenaH,
+				engine.WithTickRate(*tickRate),
+				engine.WithRules(rules),
+				engine.WithPhysics(phys),
+				engine.WithTeamSize(size),
+			)
+		},
+		MaxTicks: int(maxTime.Seconds() * float64(*tickRate)),
+		Workers:  *workers,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	}
+	fmt.Fprintf(out, "%d matches (%s, %s physics) in %s, %s of game time\n\n",
+		rep.Matches, *mode, preset, time.Since(start).Round(time.Millisecond), time.Duration(rep.GameSeconds*float64(time.Second)).Round(time.Second))
+	writeReport(out, rep)
+	return nil
+}
+
+// writeReport prints rep as a table.
+func writeReport(out io.Writer, rep sim.Report) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\twins\twin rate\tpoints\tserves\tpoints/serve")
+	for c, s := range rep.Contenders {
+		fmt.Fprintf(tw, "%c  %s\t%d\t%.1f%%\t%d\t%d\t%.2f\n", 'A'+c, s.Name, s.Wins, 100*s.WinRate, s.Points, s.Serves, s.PointsPerServe)
+	}
+	tw.Flush()
+	fmt.Fprintf(out, "\n%d rallies, on average %.1f hits and %.1f s long\n", rep.Rallies, rep.AvgRallyHits, rep.AvgRallySeconds)
+	if rep.Unfinished > 0 {
+		fmt.Fprintf(out, "%d matches undecided at the time limit\n", rep.Unfinished)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"terminalvolley/internal/sim"
+)
+
+func TestRunSim_Text(t *testing.T) {
+	t.Chdir("../..") // for config/physics.json
+	var out strings.Builder
+	err := runSim([]string{"-matches", "2", "-points", "2", "-a-level", "expert", "-b-style", "spiker"}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"2 matches (1v1, classic physics)", "A  cpu expert allround", "B  cpu normal spiker", "points/serve", "rallies, on average"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in report:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunSim_JSON(t *testing.T) {
+	t.Chdir("../..")
+	var out strings.Builder
+	if err := runSim([]string{"-matches", "2", "-points", "2", "-json"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	var rep sim.Report
+	if err := json.Unmarshal([]byte(out.String()), &rep); err != nil {
+		t.Fatalf("expected a JSON report, got %q: %v", out.String(), err)
+	}
+	if rep.Matches != 2 || rep.Contenders[0].Wins+rep.Contenders[1].Wins+rep.Unfinished != 2 {
+		t.Fatalf("unexpected report %+v", rep)
+	}
+}
+
+func TestRunSim_RejectsHumans(t *testing.T) {
+	t.Chdir("../..")
+	if err := runSim([]string{"-b", "human"}, &strings.Builder{}); err == nil {
+		t.Fatalf("expected an error for a human contender")
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"terminalvolley/internal/engine"
+	"terminalvolley/internal/input"
+	"terminalvolley/internal/render"
+)
+
+// tunedPreset is the physics preset the tuning overlay saves to.
+const tunedPreset = "tuned"
+
+// tuner is the developer overlay for live physics tuning. It lists every
+// float constant of engine.Physics by its JSON name; Up/Down select one,
+// Left/Right nudge it by a twentieth of its classic value and Ctrl+S saves
+// the result as the "tuned" preset.
+type tuner struct {
+	open   bool
+	cur    int
+	status string
+}
+
+// tunerParam is one adjustable field of engine.Physics.
+type tunerParam struct {
+	name  string
+	index int
+	step  float64
+}
+
+// tunerParams lists the float fields of engine.Physics in declaration order.
+func tunerParams() []tunerParam {
+	var params []tunerParam
+	defaults := reflect.ValueOf(engine.DefaultPhysics())
+	t := defaults.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type.Kind() != reflect.Float64 {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		step := math.Max(math.Abs(defaults.Field(i).Float())/20, 0.01)
+		params = append(params, tunerParam{name: name, index: i, step: step})
+	}
+	return params
+}
+
+// tunerAction is what a key press on the overlay asks the game to do.
+type tunerAction int
+
+const (
+	tunerNone tunerAction = iota
+	tunerChanged
+	tunerSave
+)
+
+// handle applies a key to the overlay and to phys.
+func (t *tuner) handle(ev input.Event, phys *engine.Physics) tunerAction {
+	params := tunerParams()
+	switch {
+	case ev.Key == input.KeyArrowUp:
+		t.cur = (t.cur + len(params) - 1) % len(params)
+	case ev.Key == input.KeyArrowDown:
+		t.cur = (t.cur + 1) % len(params)
+	case ev.Key == input.KeyArrowLeft || ev.Key == input.KeyArrowRight:
+		p := params[t.cur]
+		step := p.step
+		if ev.Key == input.KeyArrowLeft {
+			step = -step
+		}
+		f := reflect.ValueOf(phys).Elem().Field(p.index)
+		f.SetFloat(math.Round((f.Float()+step)*1000) / 1000)
+		return tunerChanged
+	case ev.Key == 'S' && ev.Mods&input.ModCtrl != 0:
+		return tunerSave
+	}
+	return tunerNone
+}
+
+// draw lists the constants of phys in the top right corner of frame.
+func (t *tuner) draw(frame *render.Frame, phys engine.Physics, st render.Style) {
+	params := tunerParams()
+	v := reflect.ValueOf(phys)
+	lines := []string{"PHYSICS (Up/Down, Left/Right, Ctrl+S saves)"}
+	for i, p := range params {
+		marker := "  "
+		if i == t.cur {
+			marker = "> "
+		}
+		lines = append(lines, fmt.Sprintf("%s%-16s %8.3f", marker, p.name, v.Field(p.index).Float()))
+	}
+	if t.status != "" {
+		lines = append(lines, t.status)
+	}
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, len(line))
+	}
+	x := frame.Width - width - 2
+	for i, line := range lines {
+		frame.DrawText(x, 2+i, fmt.Sprintf("%-*s", width, line), st)
+	}
+}
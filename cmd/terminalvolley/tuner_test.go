@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"terminalvolley/internal/engine"
+	"terminalvolley/internal/input"
+	"terminalvolley/internal/render"
+)
+
+func TestTunerParams_CoverEveryPhysicsConstant(t *testing.T) {
+	params := tunerParams()
+	if got, want := len(params), reflect.TypeOf(engine.Physics{}).NumField(); got != want {
+		t.Fatalf("got %d params, want %d", got, want)
+	}
+	if params[0].name != "moveSpeed" {
+		t.Fatalf("expected JSON names in declaration order, got %q first", params[0].name)
+	}
+}
+
+func TestTuner_SelectNudgeAndSave(t *testing.T) {
+	var tn tuner
+	phys := engine.DefaultPhysics()
+
+	tn.handle(input.Event{Key: input.KeyArrowDown}, &phys)
+	if got := tn.handle(input.Event{Key: input.KeyArrowRight}, &phys); got != tunerChanged {
+		t.Fatalf("expected a change, got %v", got)
+	}
+	want := engine.DefaultPhysics().AirMoveSpeed * 1.05
+	if d := phys.AirMoveSpeed - want; d < -1e-9 || d > 1e-9 {
+		t.Fatalf("expected airMoveSpeed nudged to %v, got %v", want, phys.AirMoveSpeed)
+	}
+
+	if got := tn.handle(input.Event{Key: 'S', Mods: input.ModCtrl}, &phys); got != tunerSave {
+		t.Fatalf("expected Ctrl+S to save, got %v", got)
+	}
+	if got := tn.handle(input.Event{Key: 'S'}, &phys); got != tunerNone {
+		t.Fatalf("expected plain S to be ignored, got %v", got)
+	}
+}
+
+func TestTuner_DrawListsValues(t *testing.T) {
+	tn := tuner{cur: 1, status: "saved"}
+	f := render.NewFrame(80, 24)
+	tn.draw(f, engine.DefaultPhysics(), render.Style{})
+
+	var all strings.Builder
+	for y := 0; y < f.Height; y++ {
+		all.WriteString(f.Row(y) + "\n")
+	}
+	for _, want := range []string{"  moveSpeed          28.000", "> airMoveSpeed       16.800", "saved"} {
+		if !strings.Contains(all.String(), want) {
+			t.Fatalf("expected %q in overlay, got\n%s", want, all.String())
+		}
+	}
+}
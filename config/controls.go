@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"unicode/utf8"
 )
 
 type PlayerControls struct {
@@ -13,24 +14,51 @@ type PlayerControls struct {
 	Jump  string `json:"jump"`
 }
 
+// KeyRepeat describes the terminal's auto-repeat timing, used to detect key
+// releases on terminals that do not report them. Zero means the default.
+type KeyRepeat struct {
+	DelayMs   int `json:"delayMs"`
+	TimeoutMs int `json:"timeoutMs"`
+}
+
 type Controls struct {
 	Quit       string         `json:"quit"`
 	ServeLeft  string         `json:"serveLeft"`
 	ServeRight string         `json:"serveRight"`
 	Player1    PlayerControls `json:"player1"`
 	Player2    PlayerControls `json:"player2"`
+	Player3    PlayerControls `json:"player3"`
+	Player4    PlayerControls `json:"player4"`
+	KeyRepeat  KeyRepeat      `json:"keyRepeat"`
+	DevOverlay string         `json:"devOverlay"`
 }
 
+// Normalize uppercases single-character keys. Multi-character values are
+// key names such as "ArrowLeft" or "Space" and are kept as written.
 func (c *Controls) Normalize() {
-	c.Quit = strings.ToUpper(c.Quit)
-	c.ServeLeft = strings.ToUpper(c.ServeLeft)
-	c.ServeRight = strings.ToUpper(c.ServeRight)
-	c.Player1.Left = strings.ToUpper(c.Player1.Left)
-	c.Player1.Right = strings.ToUpper(c.Player1.Right)
-	c.Player1.Jump = strings.ToUpper(c.Player1.Jump)
-	c.Player2.Left = strings.ToUpper(c.Player2.Left)
-	c.Player2.Right = strings.ToUpper(c.Player2.Right)
-	c.Player2.Jump = strings.ToUpper(c.Player2.Jump)
+	c.Quit = normalizeKey(c.Quit)
+	c.ServeLeft = normalizeKey(c.ServeLeft)
+	c.ServeRight = normalizeKey(c.ServeRight)
+	c.Player1.Left = normalizeKey(c.Player1.Left)
+	c.Player1.Right = normalizeKey(c.Player1.Right)
+	c.Player1.Jump = normalizeKey(c.Player1.Jump)
+	c.Player2.Left = normalizeKey(c.Player2.Left)
+	c.Player2.Right = normalizeKey(c.Player2.Right)
+	c.Player2.Jump = normalizeKey(c.Player2.Jump)
+	c.Player3.Left = normalizeKey(c.Player3.Left)
+	c.Player3.Right = normalizeKey(c.Player3.Right)
+	c.Player3.Jump = normalizeKey(c.Player3.Jump)
+	c.Player4.Left = normalizeKey(c.Player4.Left)
+	c.Player4.Right = normalizeKey(c.Player4.Right)
+	c.Player4.Jump = normalizeKey(c.Player4.Jump)
+	c.DevOverlay = normalizeKey(c.DevOverlay)
+}
+
+func normalizeKey(s string) string {
+	if utf8.RuneCountInString(s) != 1 {
+		return s
+	}
+	return strings.ToUpper(s)
 }
 
 func LoadControls(path string) (Controls, error) {
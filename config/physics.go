@@ -0,0 +1,125 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"terminalvolley/internal/engine"
+)
+
+// Physics is a set of named physics presets. Each preset only lists the
+// constants it changes; the rest keep engine.DefaultPhysics.
+type Physics struct {
+	Default string                    `json:"default"`
+	Presets map[string]engine.Physics `json:"-"`
+}
+
+// Names returns the preset names in alphabetical order.
+func (p Physics) Names() []string {
+	names := make([]string, 0, len(p.Presets))
+	for name := range p.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Set adds or replaces the named preset.
+func (p *Physics) Set(name string, phys engine.Physics) {
+	if p.Presets == nil {
+		p.Presets = make(map[string]engine.Physics)
+	}
+	p.Presets[name] = phys
+}
+
+// Preset returns the constants of the named preset.
+func (p Physics) Preset(name string) (engine.Physics, error) {
+	phys, ok := p.Presets[name]
+	if !ok {
+		return engine.Physics{}, fmt.Errorf("unknown physics preset %q (have %v)", name, p.Names())
+	}
+	return phys, nil
+}
+
+func LoadPhysics(path string) (Physics, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Physics{}, err
+	}
+
+	var raw struct {
+		Default string                     `json:"default"`
+		Presets map[string]json.RawMessage `json:"presets"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return Physics{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	p := Physics{Default: raw.Default, Presets: make(map[string]engine.Physics)}
+	for name, msg := range raw.Presets {
+		phys := engine.DefaultPhysics()
+		dec := json.NewDecoder(bytes.NewReader(msg))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&phys); err != nil {
+			return Physics{}, fmt.Errorf("parse %s: preset %q: %w", path, name, err)
+		}
+		if err := phys.Validate(); err != nil {
+			return Physics{}, fmt.Errorf("%s: preset %q: %w", path, name, err)
+		}
+		p.Presets[name] = phys
+	}
+	if len(p.Presets) == 0 {
+		return Physics{}, fmt.Errorf("%s: no presets", path)
+	}
+	if _, err := p.Preset(p.Default); err != nil {
+		return Physics{}, fmt.Errorf("%s: default: %w", path, err)
+	}
+	return p, nil
+}
+
+// SavePhysics writes p to path in the format LoadPhysics reads, listing for
+// each preset only the constants that differ from engine.DefaultPhysics.
+// It rewrites the whole file, with presets and constants sorted by name,
+// so any ordering or formatting made by hand is lost.
+func SavePhysics(path string, p Physics) error {
+	defaults, err := physicsFields(engine.DefaultPhysics())
+	if err != nil {
+		return err
+	}
+	out := struct {
+		Default string                        `json:"default"`
+		Presets map[string]map[string]float64 `json:"presets"`
+	}{p.Default, make(map[string]map[string]float64)}
+	for name, phys := range p.Presets {
+		fields, err := physicsFields(phys)
+		if err != nil {
+			return err
+		}
+		for k, v := range fields {
+			if defaults[k] == v {
+				delete(fields, k)
+			}
+		}
+		out.Presets[name] = fields
+	}
+
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// physicsFields returns the constants of phys keyed by their JSON names.
+func physicsFields(phys engine.Physics) (map[string]float64, error) {
+	b, err := json.Marshal(phys)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]float64
+	err = json.Unmarshal(b, &fields)
+	return fields, err
+}
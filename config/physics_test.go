@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"terminalvolley/internal/engine"
+)
+
+func writeTemp(t *testing.T, s string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "physics.json")
+	if err := os.WriteFile(path, []byte(s), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPhysics_ShippedPresets(t *testing.T) {
+	p, err := LoadPhysics("physics.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(p.Names(), ","); got != "arcade,classic,moon" {
+		t.Fatalf("unexpected presets %s", got)
+	}
+	classic, err := p.Preset(p.Default)
+	if err != nil || classic != engine.DefaultPhysics() {
+		t.Fatalf("expected classic default to match engine defaults, got %+v (%v)", classic, err)
+	}
+}
+
+func TestLoadPhysics_PartialPresetKeepsDefaults(t *testing.T) {
+	p, err := LoadPhysics(writeTemp(t, `{"default": "low", "presets": {"low": {"gravity": 10}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := engine.DefaultPhysics()
+	want.Gravity = 10
+	if got, _ := p.Preset("low"); got != want {
+		t.Fatalf("got %+v want %+v", got, want)
+	}
+}
+
+func TestLoadPhysics_Errors(t *testing.T) {
+	tests := []struct{ json, want string }{
+		{`{"default": "x", "presets": {"x": {"gravity": -1}}}`, "gravity must be positive"},
+		{`{"default": "x", "presets": {"x": {"gravty": 1}}}`, "unknown field"},
+		{`{"default": "y", "presets": {"x": {}}}`, `unknown physics preset "y"`},
+		{`{"default": "x"}`, "no presets"},
+	}
+	for _, tt := range tests {
+		_, err := LoadPhysics(writeTemp(t, tt.json))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("%s: expected error containing %q, got %v", tt.json, tt.want, err)
+		}
+	}
+}
+
+func TestSavePhysics_RoundTrip(t *testing.T) {
+	p, err := LoadPhysics("physics.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tuned := engine.DefaultPhysics()
+	tuned.PlayerKick = 12.5
+	p.Set("tuned", tuned)
+
+	path := filepath.Join(t.TempDir(), "physics.json")
+	if err := SavePhysics(path, p); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(path)
+	if !strings.Contains(string(b), `"tuned": {
+      "playerKick": 12.5
+    }`) {
+		t.Fatalf("expected only the changed constant saved, got\n%s", b)
+	}
+
+	back, err := LoadPhysics(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range p.Names() {
+		got, _ := back.Preset(name)
+		want, _ := p.Preset(name)
+		if got != want {
+			t.Fatalf("preset %s: got %+v want %+v", name, got, want)
+		}
+	}
+}
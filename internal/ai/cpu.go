@@ -0,0 +1,244 @@
+// Package ai implements computer-controlled players. They read the game
+// through its public state and play through engine.Input, the same path
+// as a keyboard.
+package ai
+
+import (
+	"math"
+	"math/rand"
+
+	"terminalvolley/internal/engine"
+)
+
+// CPU is a computer player. It predicts where the ball comes down with
+// the game's own physics, walks under it, times its jump to meet the ball
+// high and hits it from behind so it flies towards the net. How well and
+// in what style it plays is set by its Difficulty and Personality. Use
+// one CPU per player.
+type CPU struct {
+	skill Difficulty
+	style Personality
+	seed  int64
+	rng   *rand.Rand
+
+	// plans holds the decisions of the last ticks; the CPU acts on the
+	// oldest to model its reaction time.
+	plans []plan
+
+	// noise is the current prediction error, redrawn when the ball
+	// changes course; lastBall tells when that happens.
+	noise    float64
+	lastBall engine.BallState
+
+	jumping bool
+}
+
+// plan is where to stand and whether to jump.
+type plan struct {
+	target float64
+	jump   bool
+}
+
+func NewCPU(opts ...Option) *CPU {
+	c := &CPU{
+		skill: difficulties["expert"],
+		style: personalities["allround"],
+		seed:  1,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.rng = rand.New(rand.NewSource(c.seed))
+	return c
+}
+
+// court is what the CPU works out about the court from one player's view.
+type court struct {
+	g     *engine.Game
+	me    engine.PlayerState
+	phys  engine.Physics
+	net   float64
+	dir   float64 // +1 if the net is to the right of me, -1 otherwise
+	half  float64 // width of a half
+	reach float64 // blob and ball radius: distance of centers on contact
+	foot  float64 // y of a standing blob's collision center
+}
+
+func newCourt(g *engine.Game, i int) court {
+	c := court{
+		g:    g,
+		me:   g.Player(i),
+		phys: g.Physics(),
+		net:  float64(g.NetX()),
+		dir:  1,
+		half: float64(g.W) / 2,
+	}
+	if c.me.Side == engine.SideRight {
+		c.dir = -1
+	}
+	c.reach = c.phys.BlobRadius + c.phys.BallRadius
+	c.foot = float64(g.GroundY()) - 0.5
+	return c
+}
+
+// mine reports whether x is on my half.
+func (c court) mine(x float64) bool {
+	return (x < c.net) == (c.dir > 0)
+}
+
+// jumpLift is how high above standing the CPU meets the ball, and
+// riseTime how long a jump takes to get there.
+func (c court) jumpLift() float64 {
+	u := -c.phys.JumpVelocity
+	return 0.6 * u * u / (2 * c.phys.Gravity)
+}
+
+func (c court) riseTime(lift float64) float64 {
+	u := -c.phys.JumpVelocity
+	return (u - math.Sqrt(math.Max(u*u-2*c.phys.Gravity*lift, 0))) / c.phys.Gravity
+}
+
+// home is where player i waits while the ball is elsewhere. Teammates
+// spread out around the personality's spot, keeping their order from the
+// net so they never have to pass each other.
+func (cpu *CPU) home(c court, i int) float64 {
+	n := c.g.TeamSize()
+	ahead := 0 // teammates closer to the net than me
+	d := math.Abs(c.me.X - c.net)
+	for j := 0; j < c.g.NumPlayers(); j++ {
+		p := c.g.Player(j)
+		if j == i || p.Team != c.me.Team {
+			continue
+		}
+		if dj := math.Abs(p.X - c.net); dj < d || (dj == d && j > i) {
+			ahead++
+		}
+	}
+	frac := cpu.style.Home * 2 * float64(ahead+1) / float64(n+1)
+	frac = math.Min(math.Max(frac, 0.05), 0.9)
+	return c.net - c.dir*c.half*frac
+}
+
+// offset is how far behind the ball to stand so the hit sends it over the
+// net: flatter from the back of the court, steeper close to the net.
+func (cpu *CPU) offset(c court, x float64) float64 {
+	dist := math.Abs(x - c.net)
+	frac := math.Min(math.Max(0.35+0.4*dist/c.half, 0.3), 0.75) * cpu.style.Aim
+	return c.reach * math.Min(frac, 0.85)
+}
+
+// Control implements engine.Controller.
+func (cpu *CPU) Control(g *engine.Game, i int) engine.Input {
+	if g.Over() {
+		cpu.jumping = false
+		cpu.plans = cpu.plans[:0]
+		return engine.Input{}
+	}
+	c := newCourt(g, i)
+	cpu.track(g)
+
+	// Act on the plan made ReactionTime ago; until then, stand still.
+	cpu.plans = append(cpu.plans, cpu.plan(c, i))
+	delay := int(math.Round(cpu.skill.ReactionTime / g.DT()))
+	if len(cpu.plans) <= delay {
+		return engine.Input{}
+	}
+	cpu.plans = cpu.plans[len(cpu.plans)-delay-1:]
+	p := cpu.plans[0]
+
+	var in engine.Input
+	precision := math.Max(cpu.skill.Precision, 0.05)
+	dx := p.target - c.me.X
+	if g.WaitingServe() && g.Serving() == i && math.Abs(dx) <= precision && c.me.OnGround {
+		in.Serve = true
+	}
+	if math.Abs(dx) > precision {
+		in.Left, in.Right = dx < 0, dx > 0
+	}
+
+	// Keep jump held while rising for full height.
+	if cpu.jumping && (c.me.OnGround || c.me.VY >= 0) {
+		cpu.jumping = false
+	}
+	if p.jump && c.me.OnGround {
+		cpu.jumping = true
+	}
+	in.Jump = cpu.jumping
+	return in
+}
+
+// track draws a new prediction error whenever the ball changes course,
+// i.e. its velocity changes other than by gravity.
+func (cpu *CPU) track(g *engine.Game) {
+	b := g.Ball()
+	fall := cpu.lastBall.VY + g.Physics().BallGravity*g.DT()
+	if math.Abs(b.VX-cpu.lastBall.VX) > 0.5 || math.Abs(b.VY-fall) > 0.5 {
+		cpu.noise = cpu.rng.NormFloat64() * cpu.skill.Noise
+	}
+	cpu.lastBall = b
+}
+
+// plan decides where player i should stand and whether to jump now.
+func (cpu *CPU) plan(c court, i int) plan {
+	g := c.g
+	maxTicks := int(5 / g.DT())
+	lift := c.jumpLift()
+
+	if g.WaitingServe() {
+		// The ball hovers above the server wherever it goes, so toss it
+		// from home and play it like any other ball.
+		return plan{target: cpu.home(c, i)}
+	}
+
+	// Where will the ball come down to head height?
+	b, _, ok := g.PredictBall(c.foot-c.reach, maxTicks)
+	x := b.X + cpu.noise
+	if !ok || !c.mine(x) || !cpu.closest(c, i, x) {
+		return cpu.wait(c, i)
+	}
+	target := x - c.dir*cpu.offset(c, x)
+
+	// Meet it at the top of a jump if the timing works out.
+	if cpu.style.JumpHit {
+		hb, ticks, ok := g.PredictBall(c.foot-c.reach-lift, maxTicks)
+		hx := hb.X + cpu.noise
+		if ok && c.mine(hx) {
+			high := hx - c.dir*cpu.offset(c, hx)
+			rise := c.riseTime(lift)
+			if math.Abs(high-c.me.X) < c.reach*0.5 && float64(ticks)*g.DT() <= rise+g.DT() {
+				return plan{target: high, jump: true}
+			}
+		}
+	}
+	return plan{target: target}
+}
+
+// wait decides what to do while the ball is the other side's: go home,
+// or for a blocker, guard the net and jump when the ball comes close.
+func (cpu *CPU) wait(c court, i int) plan {
+	b := c.g.Ball()
+	if !cpu.style.Block || c.mine(b.X) || math.Abs(b.X-c.net) > 8 {
+		return plan{target: cpu.home(c, i)}
+	}
+	coming := b.VX*c.dir < 0
+	return plan{
+		target: c.net - c.dir*(c.phys.BlobRadius+1),
+		jump:   coming && math.Abs(b.X-c.net) < 4 && b.Y < float64(c.g.NetTop()),
+	}
+}
+
+// closest reports whether player i is the teammate nearest to x, so only
+// one blob of a team goes for the ball.
+func (cpu *CPU) closest(c court, i int, x float64) bool {
+	d := math.Abs(c.me.X - x)
+	for j := 0; j < c.g.NumPlayers(); j++ {
+		p := c.g.Player(j)
+		if j == i || p.Team != c.me.Team {
+			continue
+		}
+		if dj := math.Abs(p.X - x); dj < d || (dj == d && j < i) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package ai
+
+import (
+	"testing"
+
+	"terminalvolley/internal/engine"
+)
+
+// play runs g with the given controllers (nil players stay idle) for at
+// most ticks ticks or until the match is over.
+func play(g *engine.Game, ctrls []engine.Controller, ticks int, each func()) {
+	for t := 0; t < ticks && !g.Over(); t++ {
+		for i, c := range ctrls {
+			if c != nil {
+				g.SetInput(i, c.Control(g, i))
+			}
+		}
+		g.Step()
+		if each != nil {
+			each()
+		}
+	}
+}
+
+func TestCPU_ServesOverTheNet(t *testing.T) {
+	g := engine.New(80, 24)
+	crossed := false
+	play(g, []engine.Controller{NewCPU(), nil}, 2000, func() {
+		if b := g.Ball(); !g.WaitingServe() && b.X > float64(g.NetX())+2 {
+			crossed = true
+		}
+	})
+	if !crossed {
+		t.Fatalf("expected the CPU serve to cross the net")
+	}
+}
+
+func TestCPU_BeatsIdleOpponent(t *testing.T) {
+	for _, style := range Styles() {
+		for _, side := range []int{engine.Player1, engine.Player2} {
+			p, _ := Style(style)
+			g := engine.New(80, 24, engine.WithRules(engine.Rules{PointsToWin: 5}))
+			ctrls := make([]engine.Controller, 2)
+			ctrls[side] = NewCPU(WithPersonality(p))
+			// The idle player tosses its serves and walks off to the wall.
+			idle := 1 - side
+			play(g, ctrls, 200*600, func() {
+				away := engine.Input{Left: g.Player(idle).Side == engine.SideLeft}
+				away.Right = !away.Left
+				away.Serve = g.WaitingServe() && g.Serving() == idle
+				g.SetInput(idle, away)
+			})
+			if !g.Over() || g.Winner() != side {
+				s1, s2 := g.Score()
+				t.Fatalf("%s: expected CPU as player %d to win, over=%v score %d:%d", style, side+1, g.Over(), s1, s2)
+			}
+		}
+	}
+}
+
+func TestCPU_ExpertBeatsBeginner(t *testing.T) {
+	beginner, _ := Level("beginner")
+	expert, _ := Level("expert")
+	for seed := int64(1); seed <= 3; seed++ {
+		g := engine.New(80, 24, engine.WithRules(engine.Rules{PointsToWin: 5}))
+		play(g, []engine.Controller{
+			NewCPU(WithDifficulty(beginner), WithSeed(seed)),
+			NewCPU(WithDifficulty(expert), WithSeed(seed)),
+		}, 200*600, nil)
+		if !g.Over() || g.Winner() != engine.Team2 {
+			s1, s2 := g.Score()
+			t.Fatalf("seed %d: expected the expert to win, over=%v score %d:%d", seed, g.Over(), s1, s2)
+		}
+	}
+}
+
+func TestCPU_ReactionTimeDelaysServe(t *testing.T) {
+	d := Difficulty{ReactionTime: 0.1, Precision: 0.3}
+	for _, tc := range []struct {
+		cpu  *CPU
+		want int
+	}{{NewCPU(), 0}, {NewCPU(WithDifficulty(d)), 20}} {
+		g := engine.New(80, 24) // 200 Hz: 0.1 s is 20 ticks
+		served := -1
+		for tick := 0; tick < 100 && served < 0; tick++ {
+			if tc.cpu.Control(g, engine.Player1).Serve {
+				served = tick
+			}
+			g.Step()
+		}
+		if served != tc.want {
+			t.Fatalf("reaction %v: served at tick %d, want %d", tc.cpu.skill.ReactionTime, served, tc.want)
+		}
+	}
+}
+
+func TestCPU_PersonalityHome(t *testing.T) {
+	// Player 1 keeps the serve, so player 2 just walks to its spot.
+	dist := func(style string) float64 {
+		p, _ := Style(style)
+		g := engine.New(80, 24)
+		play(g, []engine.Controller{nil, NewCPU(WithPersonality(p))}, 400, nil)
+		return g.Player(engine.Player2).X - float64(g.NetX())
+	}
+	if rusher, back := dist("net-rusher"), dist("back-court"); rusher >= back-10 {
+		t.Fatalf("expected the net-rusher to wait well ahead of the back-court player, got %.1f vs %.1f", rusher, back)
+	}
+}
+
+func TestCPU_RalliesAgainstItself(t *testing.T) {
+	g := engine.New(80, 24, engine.WithRules(engine.Rules{PointsToWin: 3}))
+	var hits, rallies int
+	last := engine.SideLeft
+	play(g, []engine.Controller{NewCPU(), NewCPU()}, 200*600, func() {
+		b := g.Ball()
+		if g.WaitingServe() {
+			rallies++
+			return
+		}
+		if s := sideOf(g, b.X); s != last {
+			last = s
+			hits++
+		}
+	})
+	if !g.Over() {
+		t.Fatalf("expected the match to finish")
+	}
+	if hits < 4 {
+		t.Fatalf("expected the ball to cross the net back and forth, crossed %d times", hits)
+	}
+}
+
+func sideOf(g *engine.Game, x float64) engine.Side {
+	if x < float64(g.NetX()) {
+		return engine.SideLeft
+	}
+	return engine.SideRight
+}
@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Difficulty sets how well a CPU plays.
+type Difficulty struct {
+	// ReactionTime is how many seconds late the CPU acts on what it sees.
+	ReactionTime float64
+	// Noise is the standard deviation, in cells, of the error in its
+	// guess of where the ball comes down. A new error is drawn whenever
+	// the ball changes course.
+	Noise float64
+	// Precision is how close, in cells, it walks to where it wants to be.
+	Precision float64
+}
+
+// Levels are the named difficulties from weakest to strongest.
+var Levels = []string{"beginner", "easy", "normal", "hard", "expert"}
+
+var difficulties = map[string]Difficulty{
+	"beginner": {ReactionTime: 0.35, Noise: 3.0, Precision: 1.5},
+	"easy":     {ReactionTime: 0.22, Noise: 1.8, Precision: 1.0},
+	"normal":   {ReactionTime: 0.12, Noise: 1.0, Precision: 0.6},
+	"hard":     {ReactionTime: 0.06, Noise: 0.4, Precision: 0.4},
+	"expert":   {ReactionTime: 0, Noise: 0, Precision: 0.3},
+}
+
+// Level returns the named difficulty.
+func Level(name string) (Difficulty, error) {
+	d, ok := difficulties[name]
+	if !ok {
+		return Difficulty{}, fmt.Errorf("unknown CPU level %q (want one of %v)", name, Levels)
+	}
+	return d, nil
+}
+
+// Personality sets how a CPU likes to play.
+type Personality struct {
+	// Home is where it waits for the ball, as a fraction of its half
+	// measured from the net: 0 at the net, 1 at the wall.
+	Home float64
+	// Aim scales how far behind the ball it hits: above 1 sends flatter,
+	// faster shots, below 1 higher lobs.
+	Aim float64
+	// JumpHit meets the ball at the top of a jump; without it the CPU
+	// plays the ball from the ground.
+	JumpHit bool
+	// Block jumps at the net when the ball comes close on the other side.
+	Block bool
+}
+
+var personalities = map[string]Personality{
+	"allround":   {Home: 0.5, Aim: 1, JumpHit: true},
+	"net-rusher": {Home: 0.2, Aim: 0.9, JumpHit: true, Block: true},
+	"back-court": {Home: 0.75, Aim: 0.85},
+	"spiker":     {Home: 0.45, Aim: 1.3, JumpHit: true},
+}
+
+// Styles returns the personality names in alphabetical order.
+func Styles() []string {
+	names := make([]string, 0, len(personalities))
+	for name := range personalities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Style returns the named personality.
+func Style(name string) (Personality, error) {
+	p, ok := personalities[name]
+	if !ok {
+		return Personality{}, fmt.Errorf("unknown CPU style %q (want one of %v)", name, Styles())
+	}
+	return p, nil
+}
+
+// Option configures a CPU.
+type Option func(*CPU)
+
+// WithDifficulty sets the CPU's skill; the default is the "expert" level.
+func WithDifficulty(d Difficulty) Option {
+	return func(c *CPU) { c.skill = d }
+}
+
+// WithPersonality sets the CPU's style; the default is "allround".
+func WithPersonality(p Personality) Option {
+	return func(c *CPU) { c.style = p }
+}
+
+// WithSeed seeds the CPU's prediction errors, making its play repeatable.
+func WithSeed(seed int64) Option {
+	return func(c *CPU) { c.seed = seed }
+}
@@ -0,0 +1,34 @@
+package ai
+
+import "testing"
+
+func TestLevel(t *testing.T) {
+	for _, name := range Levels {
+		if _, err := Level(name); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	easy, _ := Level("easy")
+	hard, _ := Level("hard")
+	if easy.ReactionTime <= hard.ReactionTime || easy.Noise <= hard.Noise || easy.Precision <= hard.Precision {
+		t.Fatalf("expected easy to play worse than hard, got %+v vs %+v", easy, hard)
+	}
+	if _, err := Level("godlike"); err == nil {
+		t.Fatalf("expected error for unknown level")
+	}
+}
+
+func TestStyle(t *testing.T) {
+	want := []string{"allround", "back-court", "net-rusher", "spiker"}
+	if got := Styles(); len(got) != len(want) {
+		t.Fatalf("got styles %v want %v", got, want)
+	}
+	for _, name := range want {
+		if _, err := Style(name); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if _, err := Style("libero"); err == nil {
+		t.Fatalf("expected error for unknown style")
+	}
+}
@@ -0,0 +1,280 @@
+// Package bot lets external programs play. A bot is any executable that
+// reads JSON lines on stdin and writes JSON lines on stdout: a Hello
+// first, then a State every tick, each answered with an Intent naming the
+// state's tick. Anything a bot wants to log goes to stderr.
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"terminalvolley/internal/engine"
+)
+
+// DefaultTimeout is how long a tick waits for a bot's reply: one tick at
+// the default tick rate.
+const DefaultTimeout = time.Second / engine.DefaultTickRate
+
+// Bot is a player run by an external program. It implements
+// engine.Controller: each Control sends the state and waits up to the
+// timeout for the reply. A late bot keeps its last intent until a fresh
+// one arrives, and a bot that exits or writes a line that is not an
+// Intent is stopped and stands still for the rest of the match.
+type Bot struct {
+	name    string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	timeout time.Duration
+	stderr  io.Writer
+
+	greeted bool
+	replies chan Intent
+	done    chan struct{} // closed when the bot stopped talking
+	last    Intent
+	tick    int // the game's tick at the last Control
+	serve   bool
+	late    int
+
+	// pending is the newest state not yet written. The next state goes
+	// out only once the bot answered the last one, so a slow bot skips
+	// ticks instead of falling ever further behind.
+	mu       sync.Mutex
+	wake     *sync.Cond
+	pending  []byte
+	inFlight bool
+	closed   bool
+
+	errMu sync.Mutex
+	err   error
+}
+
+// Option configures a Bot.
+type Option func(*Bot)
+
+// WithTimeout sets how long each tick waits for the bot's reply.
+func WithTimeout(d time.Duration) Option {
+	return func(b *Bot) { b.timeout = d }
+}
+
+// WithStderr sends the bot's stderr to w; by default it is discarded.
+func WithStderr(w io.Writer) Option {
+	return func(b *Bot) { b.stderr = w }
+}
+
+// Start runs the program at path with args as a bot.
+func Start(path string, args []string, opts ...Option) (*Bot, error) {
+	b := &Bot{
+		name:    path,
+		cmd:     exec.Command(path, args...),
+		timeout: DefaultTimeout,
+		stderr:  io.Discard,
+		replies: make(chan Intent, 64),
+		done:    make(chan struct{}),
+		last:    Intent{Tick: -1},
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.wake = sync.NewCond(&b.mu)
+	b.cmd.Stderr = b.stderr
+
+	stdin, err := b.cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("bot %s: %w", path, err)
+	}
+	stdout, err := b.cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("bot %s: %w", path, err)
+	}
+	if err := b.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("bot %s: %w", path, err)
+	}
+	b.stdin = stdin
+	go b.read(stdout)
+	go b.write()
+	return b, nil
+}
+
+// Control implements engine.Controller.
+func (b *Bot) Control(g *engine.Game, i int) engine.Input {
+	if b.Err() != nil {
+		return engine.Input{}
+	}
+	if !b.greeted {
+		// Nothing else is queued yet, so the hello goes out directly.
+		b.greeted = true
+		line, _ := json.Marshal(NewHello(g, i))
+		b.stdin.Write(append(line, '\n'))
+	}
+	tick := g.Ticks()
+	if tick < b.tick {
+		// The game was reset for a rematch and counts ticks from 0 again.
+		b.last = Intent{Tick: -1}
+	}
+	b.tick = tick
+	b.send(NewState(g))
+
+	timer := time.NewTimer(b.timeout)
+	defer timer.Stop()
+wait:
+	for b.last.Tick < tick {
+		select {
+		case in := <-b.replies:
+			b.take(in, tick)
+		case <-b.done:
+			return engine.Input{}
+		case <-timer.C:
+			b.late++
+			break wait
+		}
+	}
+	// Take replies that already arrived without waiting.
+	for len(b.replies) > 0 {
+		b.take(<-b.replies, tick)
+	}
+
+	in := b.last.Input()
+	in.Serve, b.serve = b.serve, false
+	return in
+}
+
+// take makes in the bot's current intent at the game's tick; a serve
+// request is kept until it has been passed on once. A reply for a later
+// tick answers a state from before a rematch and is dropped.
+func (b *Bot) take(in Intent, tick int) {
+	if in.Tick < b.last.Tick || in.Tick > tick {
+		return
+	}
+	b.last = in
+	b.serve = b.serve || in.Serve
+}
+
+// Late returns how many ticks the bot missed its reply.
+func (b *Bot) Late() int { return b.late }
+
+// Err returns why the bot stopped, or nil while it plays.
+func (b *Bot) Err() error {
+	b.errMu.Lock()
+	defer b.errMu.Unlock()
+	return b.err
+}
+
+func (b *Bot) fail(err error) {
+	b.errMu.Lock()
+	defer b.errMu.Unlock()
+	if b.err == nil {
+		b.err = fmt.Errorf("bot %s: %w", b.name, err)
+	}
+}
+
+// Close stops the bot: it closes its stdin, gives it a moment to exit and
+// kills it otherwise.
+func (b *Bot) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.wake.Signal()
+	b.mu.Unlock()
+
+	b.stdin.Close()
+	select {
+	case <-b.done:
+	case <-time.After(time.Second):
+		b.cmd.Process.Kill()
+		<-b.done
+	}
+	return nil
+}
+
+// send queues s as the next line for the bot, replacing a state the bot
+// has not read yet.
+func (b *Bot) send(s State) {
+	line, err := json.Marshal(s)
+	if err != nil {
+		b.fail(err)
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = line
+	b.wake.Signal()
+}
+
+// write feeds queued states to the bot's stdin, one per reply, until it
+// is closed.
+func (b *Bot) write() {
+	for {
+		b.mu.Lock()
+		for (b.pending == nil || b.inFlight) && !b.closed {
+			b.wake.Wait()
+		}
+		if b.closed {
+			b.mu.Unlock()
+			return
+		}
+		line := b.pending
+		b.pending, b.inFlight = nil, true
+		b.mu.Unlock()
+
+		// A failed write means the bot is gone; read reports why.
+		if _, err := b.stdin.Write(append(line, '\n')); err != nil {
+			return
+		}
+	}
+}
+
+// queue hands in to Control, dropping the oldest reply if the bot runs
+// far ahead of the game.
+func (b *Bot) queue(in Intent) {
+	for {
+		select {
+		case b.replies <- in:
+			return
+		default:
+		}
+		select {
+		case <-b.replies:
+		default:
+		}
+	}
+}
+
+// read collects the bot's replies until it exits or sends garbage, then
+// reaps the process.
+func (b *Bot) read(stdout io.Reader) {
+	defer close(b.done)
+	sc := bufio.NewScanner(stdout)
+	for sc.Scan() {
+		var in Intent
+		if err := json.Unmarshal(sc.Bytes(), &in); err != nil {
+			b.fail(fmt.Errorf("bad reply %q: %w", sc.Text(), err))
+			b.cmd.Process.Kill()
+			break
+		}
+		b.mu.Lock()
+		b.inFlight = false
+		b.wake.Signal()
+		b.mu.Unlock()
+		b.queue(in)
+	}
+	err := b.cmd.Wait()
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return
+	}
+	if err == nil {
+		err = errors.New("exited")
+	}
+	b.fail(err)
+}
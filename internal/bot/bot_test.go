@@ -0,0 +1,183 @@
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"terminalvolley/internal/engine"
+)
+
+// The test binary doubles as the bots under test: started with
+// -bot-helper <mode> it plays instead of running tests.
+func TestMain(m *testing.M) {
+	if len(os.Args) == 3 && os.Args[1] == "-bot-helper" {
+		helper(os.Args[2])
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// helper walks right and serves whenever it may. Modes make it crash,
+// stall, answer slowly, talk nonsense or turn left after tick 20.
+func helper(mode string) {
+	sc := bufio.NewScanner(os.Stdin)
+	var hello Hello
+	for n := 0; sc.Scan(); n++ {
+		if n == 0 {
+			json.Unmarshal(sc.Bytes(), &hello)
+			continue
+		}
+		var s State
+		json.Unmarshal(sc.Bytes(), &s)
+		switch mode {
+		case "crash":
+			fmt.Fprintln(os.Stderr, "bot: giving up")
+			os.Exit(3)
+		case "silent":
+			continue
+		case "garbage":
+			fmt.Println("hello world")
+			continue
+		case "slow":
+			time.Sleep(20 * time.Millisecond)
+		}
+		serve := s.Serve.Waiting && s.Serve.Player == hello.Player
+		right := mode != "turn" || s.Tick < 20
+		line, _ := json.Marshal(Intent{Tick: s.Tick, Left: !right, Right: right, Serve: serve})
+		fmt.Println(string(line))
+	}
+}
+
+func startHelper(t *testing.T, mode string, opts ...Option) *Bot {
+	t.Helper()
+	b, err := Start(os.Args[0], []string{"-bot-helper", mode}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+func TestBot_PlaysThroughControl(t *testing.T) {
+	g := engine.New(80, 24)
+	b := startHelper(t, "right", WithTimeout(time.Second))
+	x := g.Player(engine.Player1).X
+	for i := 0; i < 20; i++ {
+		g.SetInput(engine.Player1, b.Control(g, engine.Player1))
+		g.Step()
+	}
+	if g.WaitingServe() {
+		t.Fatalf("expected the bot to serve")
+	}
+	if got := g.Player(engine.Player1).X; got <= x {
+		t.Fatalf("expected the bot to walk right from %.1f, at %.1f", x, got)
+	}
+	if b.Late() != 0 || b.Err() != nil {
+		t.Fatalf("expected a prompt, healthy bot, late %d err %v", b.Late(), b.Err())
+	}
+}
+
+func TestBot_FollowsRematch(t *testing.T) {
+	g := engine.New(80, 24)
+	b := startHelper(t, "turn", WithTimeout(time.Second))
+	var in engine.Input
+	for i := 0; i < 50; i++ {
+		in = b.Control(g, engine.Player1)
+		g.SetInput(engine.Player1, in)
+		g.Step()
+	}
+	if !in.Left {
+		t.Fatalf("expected the bot to turn left by tick 50, got %+v", in)
+	}
+	g.Reset()
+	for i := 0; i < 5; i++ {
+		in = b.Control(g, engine.Player1)
+		g.SetInput(engine.Player1, in)
+		g.Step()
+	}
+	if !in.Right || b.last.Tick != g.Ticks()-1 {
+		t.Fatalf("expected replies for the new match, got %+v for tick %d at tick %d", in, b.last.Tick, g.Ticks()-1)
+	}
+	if b.Late() != 0 {
+		t.Fatalf("expected no late replies, got %d", b.Late())
+	}
+}
+
+func TestBot_CrashStandsStill(t *testing.T) {
+	g := engine.New(80, 24)
+	var stderr strings.Builder
+	b := startHelper(t, "crash", WithTimeout(time.Second), WithStderr(&stderr))
+	if in := b.Control(g, engine.Player1); in != (engine.Input{}) {
+		t.Fatalf("expected no input from a crashed bot, got %+v", in)
+	}
+	if err := b.Err(); err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Fatalf("expected the exit status as error, got %v", err)
+	}
+	b.Close()
+	if !strings.Contains(stderr.String(), "giving up") {
+		t.Fatalf("expected the bot's stderr passed on, got %q", stderr.String())
+	}
+}
+
+func TestBot_GarbageStopsBot(t *testing.T) {
+	g := engine.New(80, 24)
+	b := startHelper(t, "garbage", WithTimeout(time.Second))
+	b.Control(g, engine.Player1)
+	if err := b.Err(); err == nil || !strings.Contains(err.Error(), "bad reply") {
+		t.Fatalf("expected a bad reply error, got %v", err)
+	}
+}
+
+func TestBot_TimeoutKeepsGameRunning(t *testing.T) {
+	g := engine.New(80, 24)
+	b := startHelper(t, "silent", WithTimeout(time.Millisecond))
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if in := b.Control(g, engine.Player1); in != (engine.Input{}) {
+			t.Fatalf("expected no input from a silent bot, got %+v", in)
+		}
+		g.Step()
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected each tick to give up after the timeout, took %v", elapsed)
+	}
+	if b.Late() != 10 || b.Err() != nil {
+		t.Fatalf("expected 10 late ticks and no error, got %d, %v", b.Late(), b.Err())
+	}
+}
+
+func TestBot_SlowBotCatchesUp(t *testing.T) {
+	g := engine.New(80, 24)
+	b := startHelper(t, "slow", WithTimeout(time.Millisecond))
+	for i := 0; i < 60; i++ {
+		g.SetInput(engine.Player1, b.Control(g, engine.Player1))
+		g.Step()
+		time.Sleep(time.Millisecond)
+	}
+	if b.Late() == 0 {
+		t.Fatalf("expected the slow bot to miss ticks")
+	}
+	if !g.Held(engine.Player1).Right {
+		t.Fatalf("expected the slow bot's last intent to stay held")
+	}
+}
+
+func TestNewState(t *testing.T) {
+	g := engine.New(80, 24, engine.WithTeamSize(2))
+	g.Step()
+	s := NewState(g)
+	if s.Type != "state" || s.Tick != 1 || len(s.Players) != 4 {
+		t.Fatalf("unexpected state %+v", s)
+	}
+	if s.Players[1].Side != "right" || s.Players[1].Team != engine.Team2 {
+		t.Fatalf("unexpected player 2 %+v", s.Players[1])
+	}
+	if !s.Serve.Waiting || s.Serve.Player != engine.Player1 || s.Serve.Side != "left" || s.Winner != -1 {
+		t.Fatalf("unexpected serve state %+v", s)
+	}
+}
@@ -0,0 +1,122 @@
+package bot
+
+import "terminalvolley/internal/engine"
+
+// Hello is the first line a bot reads. It says which player the bot
+// controls and describes the court.
+type Hello struct {
+	Type     string         `json:"type"` // "hello"
+	Player   int            `json:"player"`
+	Team     int            `json:"team"`
+	Width    int            `json:"width"`
+	Height   int            `json:"height"`
+	TickRate int            `json:"tickRate"`
+	NetX     int            `json:"netX"`
+	NetTop   int            `json:"netTop"`
+	GroundY  int            `json:"groundY"`
+	Physics  engine.Physics `json:"physics"`
+}
+
+// State is the game as a bot sees it at the start of a tick.
+type State struct {
+	Type    string   `json:"type"` // "state"
+	Tick    int      `json:"tick"`
+	Players []Player `json:"players"`
+	Ball    Ball     `json:"ball"`
+	Score   [2]int   `json:"score"` // per team
+	Sets    [2]int   `json:"sets"`  // per team
+	Touches [2]int   `json:"touches"`
+	Serve   Serve    `json:"serve"`
+	Over    bool     `json:"over"`
+	Winner  int      `json:"winner"` // team, -1 while undecided
+}
+
+// Player is one blob; Side is "left" or "right".
+type Player struct {
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	VX       float64 `json:"vx"`
+	VY       float64 `json:"vy"`
+	OnGround bool    `json:"onGround"`
+	Side     string  `json:"side"`
+	Team     int     `json:"team"`
+}
+
+// Ball is the ball's position and velocity.
+type Ball struct {
+	X  float64 `json:"x"`
+	Y  float64 `json:"y"`
+	VX float64 `json:"vx"`
+	VY float64 `json:"vy"`
+}
+
+// Serve tells whether the ball waits for a serve and who serves it.
+type Serve struct {
+	Waiting bool   `json:"waiting"`
+	Player  int    `json:"player"`
+	Side    string `json:"side"`
+}
+
+// Intent is a bot's reply to a State: the actions to hold for the tick
+// it names. Serve is a one-shot request.
+type Intent struct {
+	Tick  int  `json:"tick"`
+	Left  bool `json:"left"`
+	Right bool `json:"right"`
+	Jump  bool `json:"jump"`
+	Serve bool `json:"serve"`
+}
+
+// Input returns the intent as engine input.
+func (in Intent) Input() engine.Input {
+	return engine.Input{Left: in.Left, Right: in.Right, Jump: in.Jump, Serve: in.Serve}
+}
+
+// NewHello describes g to the bot playing player.
+func NewHello(g *engine.Game, player int) Hello {
+	return Hello{
+		Type:     "hello",
+		Player:   player,
+		Team:     g.Player(player).Team,
+		Width:    g.W,
+		Height:   g.H,
+		TickRate: int(1/g.DT() + 0.5),
+		NetX:     g.NetX(),
+		NetTop:   g.NetTop(),
+		GroundY:  g.GroundY(),
+		Physics:  g.Physics(),
+	}
+}
+
+// NewState captures g for a bot.
+func NewState(g *engine.Game) State {
+	s := State{
+		Type:    "state",
+		Tick:    g.Ticks(),
+		Players: make([]Player, g.NumPlayers()),
+		Touches: [2]int{g.Touches(engine.SideLeft), g.Touches(engine.SideRight)},
+		Serve: Serve{
+			Waiting: g.WaitingServe(),
+			Player:  g.Serving(),
+			Side:    sideName(g.Server()),
+		},
+		Over:   g.Over(),
+		Winner: g.Winner(),
+	}
+	for i := range s.Players {
+		p := g.Player(i)
+		s.Players[i] = Player{X: p.X, Y: p.Y, VX: p.VX, VY: p.VY, OnGround: p.OnGround, Side: sideName(p.Side), Team: p.Team}
+	}
+	b := g.Ball()
+	s.Ball = Ball{X: b.X, Y: b.Y, VX: b.VX, VY: b.VY}
+	s.Score[0], s.Score[1] = g.Score()
+	s.Sets[0], s.Sets[1] = g.Sets()
+	return s
+}
+
+func sideName(s engine.Side) string {
+	if s == engine.SideLeft {
+		return "left"
+	}
+	return "right"
+}
@@ -0,0 +1,752 @@
+// Package engine implements the TerminalVolley match simulation. It knows
+// nothing about terminals or key bytes: front-ends, bots and tools drive a
+// Game through actions and read it back through state snapshots.
+package engine
+
+import "math"
+
+// Player indexes accepted by Press and Player. Players alternate between
+// the teams: even indexes play for Team1, odd ones for Team2.
+const (
+	Player1 = 0
+	Player2 = 1
+	Player3 = 2
+	Player4 = 3
+)
+
+// Team indexes used by Score, Sets and Winner.
+const (
+	Team1 = 0
+	Team2 = 1
+)
+
+// MaxTeamSize is the most blobs a team may field.
+const MaxTeamSize = 3
+
+// Action is an input a player can issue to the simulation.
+type Action uint8
+
+const (
+	ActionLeft Action = iota
+	ActionRight
+	ActionJump
+	// ActionServe puts the ball into play while a serve is pending.
+	ActionServe
+)
+
+// DefaultTickRate is the number of simulation steps per second used when
+// no WithTickRate option is given. The ball cannot tunnel through blobs or
+// the net at any rate from MinTickRate to MaxTickRate.
+const (
+	DefaultTickRate = 200
+	MinTickRate     = 30
+	MaxTickRate     = 1000
+)
+
+// Option configures a Game at construction time.
+type Option func(*Game)
+
+// WithTickRate sets how many times per second Step is expected to be called,
+// clamped to MinTickRate..MaxTickRate.
+func WithTickRate(hz int) Option {
+	return func(g *Game) {
+		g.dt = 1.0 / float64(min(max(hz, MinTickRate), MaxTickRate))
+	}
+}
+
+// WithTeamSize sets how many blobs play for each team: 1 for the classic
+// duel, 2 for doubles. Sizes are clamped to 1..MaxTeamSize.
+func WithTeamSize(n int) Option {
+	return func(g *Game) {
+		n = min(max(n, 1), MaxTeamSize)
+		g.players = make([]player, 2*n)
+		for i := range g.players {
+			g.players[i].team = i % 2
+		}
+	}
+}
+
+// WithPhysics replaces the default tuning constants.
+func WithPhysics(p Physics) Option {
+	return func(g *Game) { g.phys = p }
+}
+
+// PlayerState is a read-only snapshot of one blob. (X, Y) is the bottom
+// center of the blob in cells.
+type PlayerState struct {
+	X, Y     float64
+	VX, VY   float64
+	OnGround bool
+	Side     Side
+	Team     int
+}
+
+// BallState is a read-only snapshot of the ball center and velocity.
+type BallState struct {
+	X, Y   float64
+	VX, VY float64
+}
+
+type player struct {
+	team int
+
+	x, y     float64
+	vx, vy   float64
+	prevX    float64
+	onGround bool
+
+	leftHeld, rightHeld bool
+	jumpHeld            bool
+	jumpReq             bool
+
+	// jumpAge is how long jumpReq has waited for the ground, airTime how
+	// long the blob has been off the ground. jumped is set from take-off
+	// to landing and rising until the jump is cut or peaks.
+	jumpAge float64
+	airTime float64
+	jumped  bool
+	rising  bool
+
+	// touching is set while the ball is in contact, so one contact
+	// counts as a single touch however many ticks it lasts.
+	touching bool
+
+	// fromX, fromY is where the blob started the current tick.
+	fromX, fromY float64
+}
+
+// centerAt returns the center of the blob's collision circle at fraction
+// t of its move this tick.
+func (p *player) centerAt(t float64) (x, y float64) {
+	return p.fromX + (p.x-p.fromX)*t, p.fromY + (p.y-p.fromY)*t - 0.5
+}
+
+type Game struct {
+	W, H int
+
+	dt    float64
+	phys  Physics
+	rules Rules
+	ticks int
+
+	groundY     int
+	groundBallY float64
+
+	netX       int
+	netTopY    int
+	netBottomY int
+
+	// While waitingServe the ball hovers above the serving blob until it
+	// is tossed or hit. server is the serving side, serving the index of
+	// the serving player and nextServer the member of each team that
+	// serves when the team wins the serve back.
+	waitingServe bool
+	server       Side
+	serving      int
+	nextServer   [2]int
+
+	players []player
+	sides   [2]Side // half of each team
+
+	// ball
+	bx, by float64
+	vx, vy float64
+
+	// touches counts contacts per side since the ball last crossed the
+	// net; ballSide is the half the ball was over at the last tick.
+	touches  [2]int
+	ballSide Side
+
+	// per team
+	score  [2]int
+	sets   [2]int
+	points [2]int // scored over all sets
+	hits   [2]int // ball contacts over the match
+	over   bool
+	winner int
+}
+
+// New creates a match on a w x h cell arena, waiting for the first serve.
+func New(w, h int, opts ...Option) *Game {
+	g := &Game{
+		W:     w,
+		H:     h,
+		dt:    1.0 / DefaultTickRate,
+		phys:  DefaultPhysics(),
+		rules: DefaultRules(),
+
+		groundY:     h - 2,
+		groundBallY: float64(h - 2),
+
+		netX:       w / 2,
+		netTopY:    h - 8,
+		netBottomY: h - 2,
+	}
+	WithTeamSize(1)(g)
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.Reset()
+	return g
+}
+
+// Reset starts a new match with the same arena, physics and rules.
+func (g *Game) Reset() {
+	g.ticks = 0
+	g.score = [2]int{}
+	g.sets = [2]int{}
+	g.points = [2]int{}
+	g.hits = [2]int{}
+	g.over = false
+	g.winner = -1
+
+	g.sides = [2]Side{SideLeft, SideRight}
+	g.placePlayers()
+
+	g.waitingServe = true
+	g.nextServer = [2]int{}
+	g.startServing(Team1)
+	g.resetServe()
+}
+
+// placePlayers puts every blob on the ground at the start position of its
+// half and stops it; teammates spread out evenly. Held keys stay held.
+func (g *Game) placePlayers() {
+	half := float64(g.W) / 2
+	n := g.TeamSize()
+	for i := range g.players {
+		p := &g.players[i]
+		*p = player{
+			team:      p.team,
+			leftHeld:  p.leftHeld,
+			rightHeld: p.rightHeld,
+			jumpHeld:  p.jumpHeld,
+		}
+		p.x = half * float64(i/2+1) / float64(n+1)
+		if g.sides[p.team] == SideRight {
+			p.x += half
+		}
+		p.y = float64(g.groundY)
+		p.onGround = true
+		p.prevX = p.x
+	}
+}
+
+func (g *Game) WaitingServe() bool { return g.waitingServe }
+
+// Server returns the side that serves next, or served the current rally.
+func (g *Game) Server() Side { return g.server }
+
+// Serving returns the index of the player who serves next, or served the
+// current rally.
+func (g *Game) Serving() int { return g.serving }
+
+// NumPlayers returns how many blobs are on the court.
+func (g *Game) NumPlayers() int { return len(g.players) }
+
+// TeamSize returns how many blobs play for each team.
+func (g *Game) TeamSize() int { return len(g.players) / 2 }
+
+// Score returns the points of Team1 and Team2 in the current set.
+func (g *Game) Score() (int, int) { return g.score[Team1], g.score[Team2] }
+
+// Sets returns the sets won by Team1 and Team2.
+func (g *Game) Sets() (int, int) { return g.sets[Team1], g.sets[Team2] }
+
+// Points returns the points Team1 and Team2 have scored in the match,
+// over all sets.
+func (g *Game) Points() (int, int) { return g.points[Team1], g.points[Team2] }
+
+// Hits returns how often Team1 and Team2 have touched the ball in the
+// match.
+func (g *Game) Hits() (int, int) { return g.hits[Team1], g.hits[Team2] }
+
+// Rules returns the match rules in use.
+func (g *Game) Rules() Rules { return g.rules }
+
+// Over reports whether the match has been decided.
+func (g *Game) Over() bool { return g.over }
+
+// Ticks returns the number of steps since the match started.
+func (g *Game) Ticks() int { return g.ticks }
+
+// Winner returns the team that won the match, or -1 while it is still
+// being played. In a duel the team index is also the player index.
+func (g *Game) Winner() int { return g.winner }
+
+// NetX returns the column of the net.
+func (g *Game) NetX() int { return g.netX }
+
+// NetTop returns the row of the top of the net.
+func (g *Game) NetTop() int { return g.netTopY }
+
+// Touches returns how often side s has touched the ball since it last
+// crossed the net.
+func (g *Game) Touches(s Side) int {
+	return g.touches[s]
+}
+
+// Physics returns the tuning constants in use.
+func (g *Game) Physics() Physics { return g.phys }
+
+// SetPhysics swaps the tuning constants mid-match, e.g. for live tuning.
+// Invalid constants are rejected and the old ones kept.
+func (g *Game) SetPhysics(p Physics) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	g.phys = p
+	return nil
+}
+
+// Ball returns a snapshot of the ball.
+func (g *Game) Ball() BallState {
+	return BallState{X: g.bx, Y: g.by, VX: g.vx, VY: g.vy}
+}
+
+// Player returns a snapshot of player i, 0 <= i < NumPlayers.
+func (g *Game) Player(i int) PlayerState {
+	p := g.players[i]
+	return PlayerState{
+		X: p.x, Y: p.y,
+		VX: p.vx, VY: p.vy,
+		OnGround: p.onGround,
+		Side:     g.sides[p.team],
+		Team:     p.team,
+	}
+}
+
+// Press starts action a for player i. Movement and jump stay held until
+// the matching Release; holding both directions cancels out.
+func (g *Game) Press(i int, a Action) {
+	if i < 0 || i >= len(g.players) {
+		return
+	}
+	p := &g.players[i]
+	switch a {
+	case ActionLeft:
+		p.leftHeld = true
+	case ActionRight:
+		p.rightHeld = true
+	case ActionJump:
+		if !p.jumpHeld {
+			p.jumpReq = true
+			p.jumpAge = 0
+		}
+		p.jumpHeld = true
+	case ActionServe:
+		// Any player of the serving side can toss for the server;
+		// the server hitting the ball serves too.
+		if g.waitingServe && !g.over && g.sides[p.team] == g.server {
+			g.vx, g.vy = 0, -g.phys.ServeToss
+			g.waitingServe = false
+		}
+	}
+}
+
+// Release ends action a for player i.
+func (g *Game) Release(i int, a Action) {
+	if i < 0 || i >= len(g.players) {
+		return
+	}
+	p := &g.players[i]
+	switch a {
+	case ActionLeft:
+		p.leftHeld = false
+	case ActionRight:
+		p.rightHeld = false
+	case ActionJump:
+		p.jumpHeld = false
+	}
+}
+
+// serveHeight is how far above the ground the ball hovers before a serve:
+// out of reach standing, in reach with a jump.
+const serveHeight = 7
+
+// resetServe parks the ball above the serving blob.
+func (g *Game) resetServe() {
+	g.holdServe()
+	g.vx, g.vy = 0, 0
+
+	g.touches = [2]int{}
+	g.ballSide = g.sideOf(g.bx)
+	for i := range g.players {
+		g.players[i].touching = false
+	}
+}
+
+// holdServe keeps the waiting ball above the serving blob.
+func (g *Game) holdServe() {
+	p := &g.players[g.serving]
+	g.bx = p.x
+	g.by = math.Max(float64(g.groundY)-serveHeight, 1+g.phys.BallRadius)
+}
+
+// hitPlayer bounces the ball off a blob centered at (cx, cy) and reports
+// whether they were in contact.
+func (g *Game) hitPlayer(cx, cy, pvx float64) bool {
+	dx := g.bx - cx
+	dy := g.by - cy
+	d2 := dx*dx + dy*dy
+	reach := g.phys.BlobRadius + g.phys.BallRadius
+	if d2 > reach*reach || d2 <= 0.0001 {
+		return false
+	}
+	d := math.Sqrt(d2)
+	nx, ny := dx/d, dy/d
+
+	penetration := reach - d
+	g.bx += nx * penetration
+	g.by += ny * penetration
+
+	dot := g.vx*nx + g.vy*ny
+	if dot < 0 {
+		g.vx = g.vx - 2*dot*nx
+		g.vy = g.vy - 2*dot*ny
+	}
+
+	g.vx += nx * g.phys.PlayerKick
+	g.vy += ny * g.phys.PlayerKick
+
+	g.vx += pvx * g.phys.PlayerCarry
+
+	g.vx *= 0.98
+	g.vy *= 0.98
+	return true
+}
+
+func (g *Game) stepPlayer(p *player, minX, maxX float64) {
+	dt := g.dt
+
+	// movement command from held state
+	hx := 0.0
+	spd := g.phys.MoveSpeed
+	if !p.onGround {
+		spd = g.phys.AirMoveSpeed
+	}
+	if p.leftHeld {
+		hx -= spd
+	}
+	if p.rightHeld {
+		hx += spd
+	}
+
+	// integrate horizontal, clamped to the player's half
+	p.x += hx * dt
+	if p.x < minX {
+		p.x = minX
+	}
+	if p.x > maxX {
+		p.x = maxX
+	}
+
+	// jump, buffered until landing and with coyote-time leniency
+	if p.jumpReq {
+		if p.onGround || (!p.jumped && p.airTime <= g.phys.CoyoteTime) {
+			p.vy = g.phys.JumpVelocity
+			p.onGround = false
+			p.jumped, p.rising = true, true
+			p.jumpReq = false
+		} else {
+			p.jumpAge += dt
+			if p.jumpAge > g.phys.JumpBuffer {
+				p.jumpReq = false
+			}
+		}
+	}
+
+	// Letting go of jump while rising cuts the jump short.
+	if p.rising && (p.vy >= 0 || !p.jumpHeld) {
+		if p.vy < 0 {
+			p.vy *= g.phys.JumpCut
+		}
+		p.rising = false
+	}
+
+	// vertical integration
+	if !p.onGround {
+		p.airTime += dt
+		p.vy += g.phys.Gravity * dt
+		p.y += p.vy * dt
+		if p.y >= float64(g.groundY) {
+			p.y = float64(g.groundY)
+			p.vy = 0
+			p.onGround = true
+			p.airTime = 0
+			p.jumped, p.rising = false, false
+		}
+	}
+
+	// horizontal velocity estimate
+	p.vx = (p.x - p.prevX) / dt
+	p.prevX = p.x
+}
+
+// Step advances the simulation by one tick.
+//
+// Blobs move once per tick. The ball moves in sub-steps short enough that
+// it cannot pass through a blob or the net, with the blobs swept along
+// their path of the tick, and it never ends a tick overlapping a blob, the
+// net or the walls.
+func (g *Game) Step() {
+	g.ticks++
+
+	for i := range g.players {
+		p := &g.players[i]
+		p.fromX, p.fromY = p.x, p.y
+		minX, maxX := g.bounds(g.sides[p.team])
+		g.stepPlayer(p, minX, maxX)
+	}
+	g.separateTeammates()
+
+	// ---- Ball physics ----
+	if g.waitingServe {
+		if g.over {
+			return
+		}
+		// The server may also put the ball into play by jumping into it.
+		g.holdServe()
+		p := &g.players[g.serving]
+		if g.hitPlayer(p.x, p.y-0.5, p.vx) {
+			g.waitingServe = false
+			p.touching = true
+			g.touch(g.server)
+		}
+		return
+	}
+
+	// Enough sub-steps that ball and blobs close in by at most
+	// maxSubstepTravel cells per sub-step.
+	closing := math.Hypot(g.vx, g.vy) + g.phys.BallGravity*g.dt
+	var blobSpeed float64
+	for i := range g.players {
+		p := &g.players[i]
+		blobSpeed = math.Max(blobSpeed, math.Hypot(p.x-p.fromX, p.y-p.fromY)/g.dt)
+	}
+	n := g.substeps(closing + blobSpeed)
+	for k := 1; k <= n; k++ {
+		if g.stepBall(g.dt/float64(n), float64(k)/float64(n)) {
+			return
+		}
+	}
+	g.separateBall()
+}
+
+// maxSubstepTravel is how far the ball may close in on a blob or the net
+// during one sub-step, well under the ball radius so it cannot tunnel.
+// maxSubsteps bounds the work per tick for absurd speeds.
+const (
+	maxSubstepTravel = 0.25
+	maxSubsteps      = 64
+)
+
+// substeps returns how many sub-steps keep two things closing in at speed
+// within maxSubstepTravel of each other per sub-step.
+func (g *Game) substeps(speed float64) int {
+	n := int(math.Ceil(speed * g.dt / maxSubstepTravel))
+	return max(1, min(n, maxSubsteps))
+}
+
+// moveBall moves the ball by dt under gravity and bounces it off the
+// walls and the net.
+func (g *Game) moveBall(dt float64) {
+	g.vy += g.phys.BallGravity * dt
+	g.bx += g.vx * dt
+	g.by += g.vy * dt
+
+	g.collideWalls()
+	g.collideNet()
+}
+
+// stepBall moves the ball by dt and resolves its collisions, with the blobs
+// at fraction t of their move this tick. It reports whether the rally ended.
+func (g *Game) stepBall(dt, t float64) bool {
+	g.moveBall(dt)
+
+	// Crossing the net hands the ball over with fresh touch counts.
+	if s := g.sideOf(g.bx); s != g.ballSide {
+		g.ballSide = s
+		g.touches = [2]int{}
+	}
+
+	// player collisions
+	for i := range g.players {
+		p := &g.players[i]
+		cx, cy := p.centerAt(t)
+		hit := g.hitPlayer(cx, cy, p.vx)
+		if hit && !p.touching && g.touch(g.sides[p.team]) {
+			return true
+		}
+		p.touching = hit
+	}
+
+	// ground/scoring
+	if g.by >= g.groundBallY {
+		g.awardPoint(g.sideOf(g.bx).Other())
+		return true
+	}
+	return false
+}
+
+// collideWalls bounces the ball off the side walls and the ceiling.
+func (g *Game) collideWalls() {
+	ballR := g.phys.BallRadius
+	rest := g.phys.BallRestitution
+
+	// walls
+	if g.bx <= 1+ballR {
+		g.bx = 1 + ballR
+		g.vx = math.Abs(g.vx) * rest
+	} else if g.bx >= float64(g.W-2)-ballR {
+		g.bx = float64(g.W-2) - ballR
+		g.vx = -math.Abs(g.vx) * rest
+	}
+
+	// ceiling
+	if g.by <= 1+ballR {
+		g.by = 1 + ballR
+		g.vy = math.Abs(g.vy) * rest
+	}
+}
+
+// separateBall pushes the ball clear of blobs, net and walls without
+// changing its velocity. A collision response can push the ball from one
+// obstacle into another, e.g. off a blob into the net; a few rounds settle
+// it in the free space between them.
+func (g *Game) separateBall() {
+	reach := g.phys.BlobRadius + g.phys.BallRadius
+	for round := 0; round < 8; round++ {
+		moved := false
+		for i := range g.players {
+			p := &g.players[i]
+			cx, cy := p.centerAt(1)
+			dx, dy := g.bx-cx, g.by-cy
+			if d := math.Hypot(dx, dy); d < reach && d > 0.0001 {
+				g.bx = cx + dx/d*reach
+				g.by = cy + dy/d*reach
+				moved = true
+			}
+		}
+		vx, vy := g.vx, g.vy
+		bx, by := g.bx, g.by
+		g.collideNet()
+		g.collideWalls()
+		g.vx, g.vy = vx, vy
+		if g.bx != bx || g.by != by {
+			moved = true
+		}
+		if !moved {
+			return
+		}
+	}
+}
+
+// sideOf returns the half of the court that contains x.
+func (g *Game) sideOf(x float64) Side {
+	if x < float64(g.netX) {
+		return SideLeft
+	}
+	return SideRight
+}
+
+// touch counts a new contact by side s and reports whether it was one too
+// many, in which case the rally has already gone to the other side.
+func (g *Game) touch(s Side) bool {
+	g.touches[s]++
+	g.hits[g.teamOn(s)]++
+	if g.rules.MaxTouches > 0 && g.touches[s] > g.rules.MaxTouches {
+		g.awardPoint(s.Other())
+		return true
+	}
+	return false
+}
+
+// awardPoint gives the rally to side s. The winner of a rally serves next;
+// under side-out scoring only the serving side scores, so a rally won by
+// the receivers just hands them the serve. Afterwards the set and match
+// are settled and the game waits for the next serve.
+func (g *Game) awardPoint(s Side) {
+	w := g.teamOn(s)
+	l := 1 - w
+	g.waitingServe = true
+	sideOut := s != g.server
+	if sideOut {
+		g.server = s
+		g.startServing(w)
+	}
+	g.resetServe()
+	if sideOut && g.rules.SideOut {
+		return
+	}
+
+	g.score[w]++
+	g.points[w]++
+	if !g.rules.setWon(g.score[w], g.score[l]) {
+		return
+	}
+	g.sets[w]++
+	if g.sets[w] >= g.rules.setsToWin() {
+		g.over = true
+		g.winner = w
+		return
+	}
+
+	// Next set: fresh score, optionally swapped halves, loser serves.
+	g.score = [2]int{}
+	if g.rules.SwitchSides {
+		g.sides[Team1], g.sides[Team2] = g.sides[Team2], g.sides[Team1]
+	}
+	g.placePlayers()
+	g.server = g.sides[l]
+	g.startServing(l)
+	g.resetServe()
+}
+
+// startServing hands the serve to the next server of team t, rotating
+// through its players each time the team wins the serve.
+func (g *Game) startServing(t int) {
+	n := g.TeamSize()
+	g.serving = t + 2*g.nextServer[t]
+	g.nextServer[t] = (g.nextServer[t] + 1) % n
+	g.server = g.sides[t]
+}
+
+// teamOn returns the team playing on side s.
+func (g *Game) teamOn(s Side) int {
+	if g.sides[Team1] == s {
+		return Team1
+	}
+	return Team2
+}
+
+// bounds returns the range of x a blob on side s may stand at. The blob
+// is 3 chars wide, so keep a margin to the wall and the net.
+func (g *Game) bounds(s Side) (minX, maxX float64) {
+	if s == SideLeft {
+		return 2, float64(g.netX - 2)
+	}
+	return float64(g.netX + 2), float64(g.W - 3)
+}
+
+// separateTeammates pushes apart blobs of a team that overlap, so they
+// share their half instead of standing in each other.
+func (g *Game) separateTeammates() {
+	gap := 2 * g.phys.BlobRadius
+	for i := range g.players {
+		for j := i + 2; j < len(g.players); j += 2 {
+			a, b := &g.players[i], &g.players[j]
+			dx := b.x - a.x
+			if math.Abs(dx) >= gap || math.Abs(b.y-a.y) >= g.phys.BlobRadius {
+				continue
+			}
+			push := (gap - math.Abs(dx)) / 2
+			if dx < 0 {
+				push = -push
+			}
+			minX, maxX := g.bounds(g.sides[a.team])
+			a.x = min(max(a.x-push, minX), maxX)
+			b.x = min(max(b.x+push, minX), maxX)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package engine
+
+import "testing"
+
+func TestGame_WaitingServe_BallFrozenUntilServe(t *testing.T) {
+	g := New(80, 24, WithTickRate(200))
+
+	if !g.WaitingServe() {
+		t.Fatalf("expected waitingServe=true at start")
+	}
+
+	b1 := g.Ball()
+	g.Step()
+	b2 := g.Ball()
+
+	if b1 != b2 {
+		t.Fatalf("expected ball frozen while waitingServe; before=%+v after=%+v", b1, b2)
+	}
+
+	g.Press(Player1, ActionServe)
+	if g.WaitingServe() {
+		t.Fatalf("expected waitingServe=false after serve key")
+	}
+
+	if b := g.Ball(); b.VX != 0 || b.VY >= 0 {
+		t.Fatalf("expected the serve to toss the ball straight up, got %+v", b)
+	}
+}
+
+func TestGame_Scoring_LeftSideAwardsP2AndResetsToWaitingServe(t *testing.T) {
+	g := New(80, 24, WithTickRate(200))
+
+	// start serve so physics runs
+	g.Press(Player1, ActionServe)
+
+	// force ball to "hit ground" on left side
+	g.bx = float64(g.netX) - 10
+	g.by = g.groundBallY + 0.001
+	g.vx, g.vy = 0, 0
+
+	g.Step()
+
+	p1, p2 := g.Score()
+	if p1 != 0 || p2 != 1 {
+		t.Fatalf("expected score 0:1, got %d:%d", p1, p2)
+	}
+	if !g.WaitingServe() {
+		t.Fatalf("expected waitingServe=true after point")
+	}
+	if b := g.Ball(); b.VX != 0 || b.VY != 0 {
+		t.Fatalf("expected ball velocity reset to 0 after point, got vx=%v vy=%v", b.VX, b.VY)
+	}
+}
+
+func TestGame_Scoring_RightSideAwardsP1AndResetsToWaitingServe(t *testing.T) {
+	g := New(80, 24, WithTickRate(200))
+
+	g.Press(Player1, ActionServe)
+
+	// force ball to "hit ground" on right side
+	g.bx = float64(g.netX) + 10
+	g.by = g.groundBallY + 0.001
+	g.vx, g.vy = 0, 0
+
+	g.Step()
+
+	p1, p2 := g.Score()
+	if p1 != 1 || p2 != 0 {
+		t.Fatalf("expected score 1:0, got %d:%d", p1, p2)
+	}
+	if !g.WaitingServe() {
+		t.Fatalf("expected waitingServe=true after point")
+	}
+}
+
+func TestGame_Press_MovesPlayerWithinHalf(t *testing.T) {
+	g := New(80, 24, WithTickRate(200))
+
+	start := g.Player(Player1).X
+	g.Press(Player1, ActionRight)
+	for i := 0; i < 400; i++ {
+		g.Step()
+	}
+	p := g.Player(Player1)
+	if p.X <= start {
+		t.Fatalf("expected player 1 to move right from %v, got %v", start, p.X)
+	}
+	if p.X > float64(g.NetX()-2) {
+		t.Fatalf("expected player 1 clamped to own half, got x=%v", p.X)
+	}
+}
+
+func TestGame_Press_JumpLeavesGround(t *testing.T) {
+	g := New(80, 24, WithTickRate(200))
+
+	g.Press(Player2, ActionJump)
+	g.Step()
+	if p := g.Player(Player2); p.OnGround || p.VY >= 0 {
+		t.Fatalf("expected player 2 airborne and rising, got %+v", p)
+	}
+	if p := g.Player(Player1); !p.OnGround {
+		t.Fatalf("expected player 1 to stay grounded")
+	}
+}
+
+func TestGame_Press_InvalidPlayerIgnored(t *testing.T) {
+	g := New(80, 24)
+	g.Press(-1, ActionJump)
+	g.Press(2, ActionServe)
+	if !g.WaitingServe() {
+		t.Fatalf("expected out-of-range player to be ignored")
+	}
+}
+
+func TestGame_Release_StopsMovement(t *testing.T) {
+	g := New(80, 24, WithTickRate(200))
+
+	g.Press(Player1, ActionLeft)
+	for i := 0; i < 20; i++ {
+		g.Step()
+	}
+	g.Release(Player1, ActionLeft)
+	g.Step()
+	x := g.Player(Player1).X
+	for i := 0; i < 20; i++ {
+		g.Step()
+	}
+	if p := g.Player(Player1); p.X != x || p.VX != 0 {
+		t.Fatalf("expected player to stop after release; x %v -> %v, vx=%v", x, p.X, p.VX)
+	}
+}
+
+func TestGame_Press_BothDirectionsCancel(t *testing.T) {
+	g := New(80, 24, WithTickRate(200))
+
+	x := g.Player(Player2).X
+	g.Press(Player2, ActionLeft)
+	g.Press(Player2, ActionRight)
+	g.Step()
+	if got := g.Player(Player2).X; got != x {
+		t.Fatalf("expected no movement with both directions held, x %v -> %v", x, got)
+	}
+
+	g.Release(Player2, ActionLeft)
+	g.Step()
+	if got := g.Player(Player2).X; got <= x {
+		t.Fatalf("expected move right once left released, x %v -> %v", x, got)
+	}
+}
+
+func TestGame_Press_HeldJumpDoesNotRetrigger(t *testing.T) {
+	g := New(80, 24, WithTickRate(200))
+
+	g.Press(Player1, ActionJump)
+	g.Step()
+	for i := 0; i < 400 && !g.Player(Player1).OnGround; i++ {
+		g.Step()
+	}
+	if !g.Player(Player1).OnGround {
+		t.Fatalf("expected player to land")
+	}
+
+	// A repeated press without release must not jump again.
+	g.Press(Player1, ActionJump)
+	g.Step()
+	if !g.Player(Player1).OnGround {
+		t.Fatalf("expected held jump not to retrigger")
+	}
+
+	g.Release(Player1, ActionJump)
+	g.Press(Player1, ActionJump)
+	g.Step()
+	if g.Player(Player1).OnGround {
+		t.Fatalf("expected jump after release and press")
+	}
+}
+
+// peak jumps player 1 and returns the highest point of the jump, letting go
+// of the key after hold ticks.
+func peak(g *Game, hold int) float64 {
+	g.Press(Player1, ActionJump)
+	top := g.Player(Player1).Y
+	for i := 0; i < 1000; i++ {
+		if i == hold {
+			g.Release(Player1, ActionJump)
+		}
+		g.Step()
+		p := g.Player(Player1)
+		top = min(top, p.Y)
+		if p.OnGround {
+			break
+		}
+	}
+	g.Release(Player1, ActionJump)
+	return top
+}
+
+func TestGame_ReleasingJumpEarlyJumpsLower(t *testing.T) {
+	g := New(80, 24, WithTickRate(200))
+	ground := g.Player(Player1).Y
+	tap := ground - peak(g, 10)
+	full := ground - peak(g, 1000)
+	if tap >= full*0.6 {
+		t.Fatalf("expected a tap to jump well below a full jump, tap=%.2f full=%.2f", tap, full)
+	}
+
+	phys := DefaultPhysics()
+	phys.JumpCut = 1
+	g = New(80, 24, WithTickRate(200), WithPhysics(phys))
+	if tap := ground - peak(g, 10); tap < full-0.01 {
+		t.Fatalf("expected jumpCut 1 to disable short hops, tap=%.2f full=%.2f", tap, full)
+	}
+}
+
+func TestGame_JumpBufferedBeforeLanding(t *testing.T) {
+	for _, tt := range []struct {
+		buffer float64
+		want   bool
+	}{{0.1, true}, {0, false}} {
+		phys := DefaultPhysics()
+		phys.JumpBuffer = tt.buffer
+		g := New(80, 24, WithTickRate(200), WithPhysics(phys))
+		p := &g.players[Player1]
+		p.y, p.vy, p.onGround, p.jumped = float64(g.groundY)-0.2, 10, false, true
+
+		// Pressed in the air a few ticks before touching down.
+		g.Press(Player1, ActionJump)
+		for i := 0; i < 10 && !p.onGround; i++ {
+			g.Step()
+		}
+		g.Step()
+		if got := !p.onGround; got != tt.want {
+			t.Fatalf("buffer %v: expected jumping=%v after landing", tt.buffer, tt.want)
+		}
+	}
+}
+
+func TestGame_CoyoteTime(t *testing.T) {
+	for _, tt := range []struct {
+		air  float64
+		want bool
+	}{{0.05, true}, {0.2, false}} {
+		g := New(80, 24, WithTickRate(200))
+		p := &g.players[Player1]
+		// Stepped off an edge rather than jumped.
+		p.y, p.vy, p.onGround, p.airTime = float64(g.groundY)-3, 0, false, tt.air
+
+		g.Press(Player1, ActionJump)
+		g.Step()
+		if got := p.vy < 0; got != tt.want {
+			t.Fatalf("%.2fs in the air: expected jump=%v, vy=%v", tt.air, tt.want, p.vy)
+		}
+	}
+}
+
+func TestGame_TicksCountSteps(t *testing.T) {
+	g := New(80, 24)
+	for i := 0; i < 3; i++ {
+		g.Step()
+	}
+	if g.Ticks() != 3 {
+		t.Fatalf("expected 3 ticks, got %d", g.Ticks())
+	}
+	g.Reset()
+	if g.Ticks() != 0 {
+		t.Fatalf("expected Reset to restart the count, got %d", g.Ticks())
+	}
+}
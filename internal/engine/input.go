@@ -0,0 +1,44 @@
+package engine
+
+// Input is the set of actions a player holds during a tick. Serve is a
+// one-shot request; the others stay held until an Input without them.
+type Input struct {
+	Left, Right, Jump, Serve bool
+}
+
+// Controller decides a player's input from the state of the game, once
+// per tick. CPU players, external bots and network peers implement it.
+type Controller interface {
+	Control(g *Game, player int) Input
+}
+
+// SetInput makes player i hold exactly the actions in in, issuing the
+// Press and Release calls a keyboard would.
+func (g *Game) SetInput(i int, in Input) {
+	if i < 0 || i >= len(g.players) {
+		return
+	}
+	held := g.Held(i)
+	toggle := func(a Action, was, want bool) {
+		if want && !was {
+			g.Press(i, a)
+		} else if !want && was {
+			g.Release(i, a)
+		}
+	}
+	toggle(ActionLeft, held.Left, in.Left)
+	toggle(ActionRight, held.Right, in.Right)
+	toggle(ActionJump, held.Jump, in.Jump)
+	if in.Serve {
+		g.Press(i, ActionServe)
+	}
+}
+
+// Held returns the actions player i holds.
+func (g *Game) Held(i int) Input {
+	if i < 0 || i >= len(g.players) {
+		return Input{}
+	}
+	p := &g.players[i]
+	return Input{Left: p.leftHeld, Right: p.rightHeld, Jump: p.jumpHeld}
+}
@@ -0,0 +1,44 @@
+package engine
+
+import "math"
+
+// netRadius is the half thickness of the net. The net is a rounded post: a
+// vertical segment from the top of the net down to the ground, widened by
+// netRadius, so its top is a half circle the ball can roll off.
+const netRadius = 0.5
+
+// collideNet pushes the ball out of the net and reflects the part of its
+// velocity that points into the net, keeping the part along the surface.
+func (g *Game) collideNet() {
+	// Closest point of the net's center line to the ball.
+	cx := float64(g.netX)
+	cy := math.Max(float64(g.netTopY), math.Min(g.by, float64(g.netBottomY)))
+
+	dx := g.bx - cx
+	dy := g.by - cy
+	reach := netRadius + g.phys.BallRadius
+	d2 := dx*dx + dy*dy
+	if d2 >= reach*reach {
+		return
+	}
+
+	var nx, ny float64
+	if d2 > 1e-12 {
+		d := math.Sqrt(d2)
+		nx, ny = dx/d, dy/d
+	} else {
+		// Dead center on the post: push it back where it came from.
+		nx = -1
+		if g.vx < 0 {
+			nx = 1
+		}
+	}
+	g.bx = cx + nx*reach
+	g.by = cy + ny*reach
+
+	if dot := g.vx*nx + g.vy*ny; dot < 0 {
+		k := (1 + g.phys.BallRestitution) * dot
+		g.vx -= k * nx
+		g.vy -= k * ny
+	}
+}
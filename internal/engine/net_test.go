@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+// servedGame returns a game with the ball in play, placed at (x, y) with
+// velocity (vx, vy).
+func servedGame(x, y, vx, vy float64) *Game {
+	g := New(80, 24)
+	g.Press(Player1, ActionServe)
+	g.bx, g.by = x, y
+	g.vx, g.vy = vx, vy
+	return g
+}
+
+func TestNet_SideHitReflects(t *testing.T) {
+	g := servedGame(38.4, 20, 30, 0)
+	g.Step()
+	if g.vx >= 0 {
+		t.Fatalf("expected ball to bounce back off the net, vx=%v", g.vx)
+	}
+	if g.bx > 40-netRadius-g.phys.BallRadius+1e-9 {
+		t.Fatalf("expected ball pushed out of the net, x=%v", g.bx)
+	}
+}
+
+func TestNet_TopBouncesUp(t *testing.T) {
+	top := float64(24 - 8)
+	g := servedGame(40, top-netRadius-DefaultPhysics().BallRadius+0.05, 0, 10)
+	g.Step()
+	if g.vy >= 0 {
+		t.Fatalf("expected ball to rebound up from the net top, vy=%v", g.vy)
+	}
+}
+
+func TestNet_BallRollsOffTheTop(t *testing.T) {
+	// Slightly right of the top cap, the surface normal tips the ball over
+	// to the right instead of bouncing it back.
+	top := float64(24 - 8)
+	g := servedGame(40.3, top-netRadius-DefaultPhysics().BallRadius+0.05, 0, 10)
+	g.Step()
+	if g.vx <= 0 {
+		t.Fatalf("expected ball deflected to the right, vx=%v", g.vx)
+	}
+	for i := 0; i < 400 && !g.WaitingServe(); i++ {
+		g.Step()
+	}
+	if p1, p2 := g.Score(); p1 != 1 || p2 != 0 {
+		t.Fatalf("expected the ball to fall on the right, score %d:%d", p1, p2)
+	}
+}
+
+func TestNet_BallNeverRestsInsideNet(t *testing.T) {
+	for _, x := range []float64{38, 39.5, 40, 40.5, 42} {
+		for _, vx := range []float64{-20, -5, 5, 20} {
+			g := servedGame(x, 10, vx, 0)
+			for i := 0; i < 400 && !g.WaitingServe(); i++ {
+				g.Step()
+				cy := math.Max(float64(g.netTopY), math.Min(g.by, float64(g.netBottomY)))
+				if d := math.Hypot(g.bx-float64(g.netX), g.by-cy); d < netRadius+g.phys.BallRadius-1e-9 {
+					t.Fatalf("x=%v vx=%v tick %d: ball overlaps the net (d=%v)", x, vx, i, d)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package engine
+
+import "fmt"
+
+// Physics holds the tuning constants of the simulation. Speeds are in
+// cells/sec, accelerations in cells/sec^2 and radii in cells.
+type Physics struct {
+	MoveSpeed    float64 `json:"moveSpeed"`
+	AirMoveSpeed float64 `json:"airMoveSpeed"`
+	JumpVelocity float64 `json:"jumpVelocity"`
+	Gravity      float64 `json:"gravity"`
+
+	// JumpCut scales a rising blob's vertical speed when the jump key is
+	// let go, so short taps jump lower; 1 makes every jump full height.
+	// JumpBuffer is how many seconds a jump pressed in the air is kept
+	// for the landing, and CoyoteTime how long after leaving the ground
+	// without jumping a blob may still jump.
+	JumpCut    float64 `json:"jumpCut"`
+	JumpBuffer float64 `json:"jumpBuffer"`
+	CoyoteTime float64 `json:"coyoteTime"`
+
+	BallGravity     float64 `json:"ballGravity"`
+	BallRestitution float64 `json:"ballRestitution"`
+	PlayerKick      float64 `json:"playerKick"`
+	PlayerCarry     float64 `json:"playerCarry"`
+	ServeToss       float64 `json:"serveToss"`
+
+	BallRadius float64 `json:"ballRadius"`
+	BlobRadius float64 `json:"blobRadius"`
+}
+
+// DefaultPhysics returns the classic tuning the game ships with.
+func DefaultPhysics() Physics {
+	return Physics{
+		MoveSpeed:    28.0,
+		AirMoveSpeed: 16.8,
+		JumpVelocity: -28.0,
+		Gravity:      32.0,
+
+		JumpCut:    0.5,
+		JumpBuffer: 0.1,
+		CoyoteTime: 0.08,
+
+		BallGravity:     18.0,
+		BallRestitution: 0.78,
+		PlayerKick:      10.0,
+		PlayerCarry:     0.30,
+		ServeToss:       12.0,
+
+		BallRadius: 1.05,
+		BlobRadius: 1.7,
+	}
+}
+
+// Validate reports the first constant that would make the game unplayable,
+// named as in JSON.
+func (p Physics) Validate() error {
+	checks := []struct {
+		name string
+		ok   bool
+		want string
+	}{
+		{"moveSpeed", p.MoveSpeed > 0, "positive"},
+		{"airMoveSpeed", p.AirMoveSpeed >= 0, "zero or more"},
+		{"jumpVelocity", p.JumpVelocity < 0, "negative (up)"},
+		{"gravity", p.Gravity > 0, "positive"},
+		{"jumpCut", p.JumpCut >= 0 && p.JumpCut <= 1, "between 0 and 1"},
+		{"jumpBuffer", p.JumpBuffer >= 0 && p.JumpBuffer <= 1, "between 0 and 1 second"},
+		{"coyoteTime", p.CoyoteTime >= 0 && p.CoyoteTime <= 1, "between 0 and 1 second"},
+		{"ballGravity", p.BallGravity > 0, "positive"},
+		{"ballRestitution", p.BallRestitution >= 0 && p.BallRestitution <= 1, "between 0 and 1"},
+		{"playerKick", p.PlayerKick >= 0, "zero or more"},
+		{"playerCarry", p.PlayerCarry >= 0, "zero or more"},
+		{"serveToss", p.ServeToss >= 0, "zero or more"},
+		{"ballRadius", p.BallRadius > 0 && p.BallRadius <= 3, "between 0 and 3"},
+		{"blobRadius", p.BlobRadius > 0 && p.BlobRadius <= 3, "between 0 and 3"},
+	}
+	for _, c := range checks {
+		if !c.ok {
+			return fmt.Errorf("%s must be %s", c.name, c.want)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPhysics_Validate(t *testing.T) {
+	if err := DefaultPhysics().Validate(); err != nil {
+		t.Fatalf("expected default physics to be valid: %v", err)
+	}
+
+	tests := []struct {
+		edit func(*Physics)
+		want string
+	}{
+		{func(p *Physics) { p.MoveSpeed = 0 }, "moveSpeed"},
+		{func(p *Physics) { p.JumpVelocity = 20 }, "jumpVelocity"},
+		{func(p *Physics) { p.BallRestitution = 1.5 }, "ballRestitution"},
+		{func(p *Physics) { p.BallRadius = 0 }, "ballRadius"},
+		{func(p *Physics) { p.BlobRadius = 10 }, "blobRadius"},
+	}
+	for _, tt := range tests {
+		p := DefaultPhysics()
+		tt.edit(&p)
+		err := p.Validate()
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Fatalf("expected error about %s, got %v", tt.want, err)
+		}
+	}
+}
+
+func TestGame_SetPhysicsRejectsInvalid(t *testing.T) {
+	g := New(80, 24)
+	p := g.Physics()
+	p.Gravity = 10
+	if err := g.SetPhysics(p); err != nil || g.Physics().Gravity != 10 {
+		t.Fatalf("expected new gravity applied, err=%v", err)
+	}
+
+	p.BallRadius = -1
+	if err := g.SetPhysics(p); err == nil || g.Physics().BallRadius != DefaultPhysics().BallRadius {
+		t.Fatalf("expected invalid physics rejected, err=%v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package engine
+
+import "math"
+
+// PredictBall follows the ball on its own, with the same gravity, walls
+// and net as Step but no blobs, until it falls through height y or reaches
+// the ground. It returns the ball at that point and how many ticks away it
+// is; ok is false if that takes longer than maxTicks. While a serve is
+// pending the ball is predicted as if it had just been tossed.
+func (g *Game) PredictBall(y float64, maxTicks int) (b BallState, ticks int, ok bool) {
+	c := *g // moveBall only touches the ball
+	if c.waitingServe {
+		c.vx, c.vy = 0, -c.phys.ServeToss
+	}
+	y = math.Min(y, c.groundBallY)
+
+	for ticks = 1; ticks <= maxTicks; ticks++ {
+		n := c.substeps(math.Hypot(c.vx, c.vy) + c.phys.BallGravity*c.dt)
+		for k := 0; k < n; k++ {
+			c.moveBall(c.dt / float64(n))
+		}
+		if c.vy > 0 && c.by >= y {
+			return c.Ball(), ticks, true
+		}
+	}
+	return c.Ball(), maxTicks, false
+}
+
+// DT returns the length of one tick in seconds.
+func (g *Game) DT() float64 { return g.dt }
+
+// GroundY returns the row the blobs stand on.
+func (g *Game) GroundY() int { return g.groundY }
@@ -0,0 +1,66 @@
+package engine
+
+import "testing"
+
+func TestPredictBall_MatchesStep(t *testing.T) {
+	for _, hz := range []int{MinTickRate, DefaultTickRate} {
+		g := servedGame(30, 6, 25, -10)
+		WithTickRate(hz)(g)
+		// Keep the blobs out of the ball's way.
+		g.players[Player1].x, g.players[Player2].x = 2, 77
+
+		want, ticks, ok := g.PredictBall(12, 10*hz)
+		if !ok {
+			t.Fatalf("%d Hz: expected a prediction", hz)
+		}
+		for i := 0; i < ticks; i++ {
+			g.Step()
+		}
+		if got := g.Ball(); got != want {
+			t.Fatalf("%d Hz: predicted %+v after %d ticks, got %+v", hz, want, ticks, got)
+		}
+		if g.vy <= 0 || g.by < 12 {
+			t.Fatalf("%d Hz: expected the ball falling past y=12", hz)
+		}
+	}
+}
+
+func TestPredictBall_DoesNotChangeGame(t *testing.T) {
+	g := servedGame(30, 6, 25, -10)
+	before := g.Ball()
+	g.PredictBall(20, 1000)
+	if g.Ball() != before {
+		t.Fatalf("expected prediction to leave the ball alone")
+	}
+}
+
+func TestPredictBall_ServeToss(t *testing.T) {
+	g := New(80, 24)
+	b, _, ok := g.PredictBall(float64(g.groundY)-3, 1000)
+	if !ok || b.X != g.Player(Player1).X {
+		t.Fatalf("expected the tossed ball to come straight down, got %+v ok=%v", b, ok)
+	}
+}
+
+func TestSetInput_PressesAndReleases(t *testing.T) {
+	g := New(80, 24)
+	g.SetInput(Player1, Input{Right: true, Jump: true})
+	if got := g.Held(Player1); got != (Input{Right: true, Jump: true}) {
+		t.Fatalf("unexpected held input %+v", got)
+	}
+	g.Step()
+	if g.Player(Player1).OnGround {
+		t.Fatalf("expected the jump to fire")
+	}
+
+	g.SetInput(Player1, Input{Left: true})
+	if got := g.Held(Player1); got != (Input{Left: true}) {
+		t.Fatalf("unexpected held input %+v", got)
+	}
+
+	g.SetInput(Player1, Input{Serve: true})
+	if g.WaitingServe() {
+		t.Fatalf("expected serve to toss the ball")
+	}
+	g.SetInput(-1, Input{Left: true}) // ignored
+}
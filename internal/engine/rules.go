@@ -0,0 +1,61 @@
+package engine
+
+// Side is a half of the court.
+type Side uint8
+
+const (
+	SideLeft Side = iota
+	SideRight
+)
+
+// Other returns the opposite half.
+func (s Side) Other() Side {
+	if s == SideLeft {
+		return SideRight
+	}
+	return SideLeft
+}
+
+// Rules decide when a set and the match are over.
+type Rules struct {
+	// PointsToWin is the score that wins a set; 0 plays forever.
+	PointsToWin int
+	// WinByTwo requires a two-point lead to take the set.
+	WinByTwo bool
+	// Sets is the length of a best-of-N match; 0 or 1 plays a single set.
+	Sets int
+	// SwitchSides swaps the players' halves after each set.
+	SwitchSides bool
+	// MaxTouches is how often a side may touch the ball before it has to
+	// cross the net; one more touch is a fault. 0 allows any number.
+	MaxTouches int
+	// SideOut only lets the serving side score; the receivers win the
+	// serve instead of a point.
+	SideOut bool
+}
+
+// DefaultRules is a single set to 15, won by two points.
+func DefaultRules() Rules {
+	return Rules{PointsToWin: 15, WinByTwo: true, Sets: 1}
+}
+
+// setsToWin is the number of sets that decides a best-of-N match.
+func (r Rules) setsToWin() int {
+	if r.Sets <= 1 {
+		return 1
+	}
+	return r.Sets/2 + 1
+}
+
+// setWon reports whether a player with score pts against opp takes the set.
+func (r Rules) setWon(pts, opp int) bool {
+	if r.PointsToWin <= 0 || pts < r.PointsToWin {
+		return false
+	}
+	return !r.WinByTwo || pts-opp >= 2
+}
+
+// WithRules sets the match rules; the default is DefaultRules.
+func WithRules(r Rules) Option {
+	return func(g *Game) { g.rules = r }
+}
@@ -0,0 +1,301 @@
+package engine
+
+import "testing"
+
+// landOn serves and drops the ball onto the ground of side s, so the
+// player on the other side wins the rally.
+func landOn(g *Game, s Side) {
+	g.Press(g.serving, ActionServe)
+	g.bx = float64(g.netX) - 10
+	if s == SideRight {
+		g.bx = float64(g.netX) + 10
+	}
+	g.by = g.groundBallY + 0.001
+	g.vx, g.vy = 0, 0
+	g.Step()
+}
+
+func TestRules_SetWon(t *testing.T) {
+	tests := []struct {
+		r        Rules
+		pts, opp int
+		want     bool
+	}{
+		{Rules{PointsToWin: 0}, 100, 0, false},
+		{Rules{PointsToWin: 15}, 14, 0, false},
+		{Rules{PointsToWin: 15}, 15, 14, true},
+		{Rules{PointsToWin: 15, WinByTwo: true}, 15, 14, false},
+		{Rules{PointsToWin: 15, WinByTwo: true}, 16, 14, true},
+	}
+	for _, tt := range tests {
+		if got := tt.r.setWon(tt.pts, tt.opp); got != tt.want {
+			t.Fatalf("%+v %d:%d: got %v want %v", tt.r, tt.pts, tt.opp, got, tt.want)
+		}
+	}
+}
+
+func TestRules_SetsToWin(t *testing.T) {
+	for sets, want := range map[int]int{0: 1, 1: 1, 3: 2, 5: 3} {
+		if got := (Rules{Sets: sets}).setsToWin(); got != want {
+			t.Fatalf("best of %d: got %d want %d", sets, got, want)
+		}
+	}
+}
+
+func TestGame_SingleSetEndsMatch(t *testing.T) {
+	g := New(80, 24, WithRules(Rules{PointsToWin: 2}))
+
+	landOn(g, SideRight)
+	if g.Over() {
+		t.Fatalf("expected match to continue at 1:0")
+	}
+	landOn(g, SideRight)
+	if !g.Over() || g.Winner() != Player1 {
+		t.Fatalf("expected player 1 to win, over=%v winner=%d", g.Over(), g.Winner())
+	}
+	if s1, s2 := g.Sets(); s1 != 1 || s2 != 0 {
+		t.Fatalf("expected sets 1:0, got %d:%d", s1, s2)
+	}
+
+	// No more serves once the match is over.
+	g.Press(Player1, ActionServe)
+	if !g.WaitingServe() {
+		t.Fatalf("expected serve to be ignored after the match")
+	}
+}
+
+func TestGame_WinByTwoExtendsSet(t *testing.T) {
+	g := New(80, 24, WithRules(Rules{PointsToWin: 2, WinByTwo: true}))
+
+	landOn(g, SideRight)
+	landOn(g, SideLeft)
+	landOn(g, SideRight) // 2:1
+	if g.Over() {
+		t.Fatalf("expected 2:1 not to win with win-by-two")
+	}
+	landOn(g, SideRight) // 3:1
+	if !g.Over() || g.Winner() != Player1 {
+		t.Fatalf("expected player 1 to win 3:1")
+	}
+}
+
+func TestGame_SetsAndSideSwitch(t *testing.T) {
+	g := New(80, 24, WithRules(Rules{PointsToWin: 1, Sets: 3, SwitchSides: true}))
+
+	landOn(g, SideRight) // set 1 to player 1
+	if g.Over() {
+		t.Fatalf("expected best of 3 to continue after one set")
+	}
+	if s1, s2 := g.Sets(); s1 != 1 || s2 != 0 {
+		t.Fatalf("expected sets 1:0, got %d:%d", s1, s2)
+	}
+	if p1, p2 := g.Score(); p1 != 0 || p2 != 0 {
+		t.Fatalf("expected score reset for the new set, got %d:%d", p1, p2)
+	}
+	if g.Player(Player1).Side != SideRight || g.Player(Player2).Side != SideLeft {
+		t.Fatalf("expected players to switch sides")
+	}
+	if x := g.Player(Player1).X; x <= float64(g.NetX()) {
+		t.Fatalf("expected player 1 placed on the right half, x=%v", x)
+	}
+
+	// Player 1 now defends the right: a ball on the left is their point.
+	landOn(g, SideLeft)
+	if !g.Over() || g.Winner() != Player1 {
+		t.Fatalf("expected player 1 to win 2:0 in sets, over=%v winner=%d", g.Over(), g.Winner())
+	}
+}
+
+func TestGame_ResetStartsRematch(t *testing.T) {
+	g := New(80, 24, WithRules(Rules{PointsToWin: 1, SwitchSides: true, Sets: 3}))
+	landOn(g, SideRight)
+	landOn(g, SideLeft)
+
+	g.Reset()
+	if g.Over() || g.Winner() != -1 {
+		t.Fatalf("expected fresh match after reset")
+	}
+	if p1, p2 := g.Score(); p1 != 0 || p2 != 0 {
+		t.Fatalf("expected score 0:0, got %d:%d", p1, p2)
+	}
+	if s1, s2 := g.Sets(); s1 != 0 || s2 != 0 {
+		t.Fatalf("expected sets 0:0, got %d:%d", s1, s2)
+	}
+	if g.Player(Player1).Side != SideLeft {
+		t.Fatalf("expected player 1 back on the left")
+	}
+	if !g.WaitingServe() {
+		t.Fatalf("expected reset to wait for a serve")
+	}
+}
+
+// bump drops the ball onto player i for one tick and then lifts it clear,
+// so each call is one separate touch.
+func bump(g *Game, i int) {
+	p := &g.players[i]
+	reach := g.phys.BlobRadius + g.phys.BallRadius
+	g.bx, g.by = p.x, p.y-0.5-reach+0.1
+	g.vx, g.vy = 0, 5
+	g.Step()
+	g.bx, g.by = p.x, 3
+	g.vx, g.vy = 0, 0
+	g.Step()
+}
+
+func TestGame_FourthTouchIsAFault(t *testing.T) {
+	g := New(80, 24, WithRules(Rules{MaxTouches: 3}))
+	g.Press(Player1, ActionServe)
+
+	for n := 1; n <= 3; n++ {
+		bump(g, Player1)
+		if got := g.Touches(SideLeft); got != n {
+			t.Fatalf("touch %d: got count %d", n, got)
+		}
+	}
+	if p1, p2 := g.Score(); p1 != 0 || p2 != 0 {
+		t.Fatalf("expected three touches to be legal, score %d:%d", p1, p2)
+	}
+
+	bump(g, Player1)
+	if p1, p2 := g.Score(); p1 != 0 || p2 != 1 {
+		t.Fatalf("expected the fourth touch to give player 2 the point, score %d:%d", p1, p2)
+	}
+	if !g.WaitingServe() || g.Touches(SideLeft) != 0 {
+		t.Fatalf("expected a fresh serve after the fault")
+	}
+}
+
+func TestGame_TouchesResetWhenBallCrossesNet(t *testing.T) {
+	g := New(80, 24, WithRules(Rules{MaxTouches: 3}))
+	g.Press(Player1, ActionServe)
+
+	for n := 0; n < 3; n++ {
+		bump(g, Player1)
+	}
+	g.bx, g.by = float64(g.netX)+10, 3
+	g.Step()
+	if got := g.Touches(SideLeft); got != 0 {
+		t.Fatalf("expected touches reset after crossing, got %d", got)
+	}
+
+	bump(g, Player2)
+	g.bx, g.by = float64(g.netX)-10, 3
+	g.Step()
+	bump(g, Player1)
+	if p1, p2 := g.Score(); p1 != 0 || p2 != 0 {
+		t.Fatalf("expected no fault, score %d:%d", p1, p2)
+	}
+	if got := g.Touches(SideLeft); got != 1 {
+		t.Fatalf("expected one touch on the left, got %d", got)
+	}
+}
+
+func TestGame_ContinuousContactIsOneTouch(t *testing.T) {
+	g := New(80, 24, WithRules(Rules{MaxTouches: 1}))
+	g.Press(Player1, ActionServe)
+
+	p := &g.players[Player1]
+	reach := g.phys.BlobRadius + g.phys.BallRadius
+	for n := 0; n < 3; n++ {
+		g.bx, g.by = p.x, p.y-0.5-reach+0.1
+		g.vx, g.vy = 0, 5
+		g.Step()
+	}
+	if got := g.Touches(SideLeft); got != 1 {
+		t.Fatalf("expected one touch, got %d", got)
+	}
+}
+
+func TestGame_UnlimitedTouches(t *testing.T) {
+	g := New(80, 24, WithRules(Rules{}))
+	g.Press(Player1, ActionServe)
+	for n := 0; n < 10; n++ {
+		bump(g, Player1)
+	}
+	if p1, p2 := g.Score(); p1 != 0 || p2 != 0 {
+		t.Fatalf("expected no touch limit, score %d:%d", p1, p2)
+	}
+}
+
+func TestGame_BallHoversAboveServer(t *testing.T) {
+	g := New(80, 24)
+	g.Press(Player1, ActionRight)
+	for i := 0; i < 50; i++ {
+		g.Step()
+	}
+	b, p := g.Ball(), g.Player(Player1)
+	if b.X != p.X || b.Y >= p.Y-3 {
+		t.Fatalf("expected ball above the serving blob, ball=%+v blob=%+v", b, p)
+	}
+
+	// The receiver cannot serve.
+	g.Press(Player2, ActionServe)
+	if !g.WaitingServe() {
+		t.Fatalf("expected player 2 serve to be ignored")
+	}
+}
+
+func TestGame_ServerLaunchesBallByHittingIt(t *testing.T) {
+	g := New(80, 24, WithRules(Rules{MaxTouches: 3}))
+	g.Press(Player1, ActionJump)
+	for i := 0; i < 200 && g.WaitingServe(); i++ {
+		g.Step()
+	}
+	if g.WaitingServe() {
+		t.Fatalf("expected the jump to hit the ball into play")
+	}
+	if b := g.Ball(); b.VY >= 0 {
+		t.Fatalf("expected the ball to fly up, got %+v", b)
+	}
+	if got := g.Touches(SideLeft); got != 1 {
+		t.Fatalf("expected the serve hit to count as a touch, got %d", got)
+	}
+}
+
+func TestGame_RallyWinnerServes(t *testing.T) {
+	g := New(80, 24)
+	landOn(g, SideLeft)
+	if g.Server() != SideRight {
+		t.Fatalf("expected player 2 to serve after winning the rally")
+	}
+	if b := g.Ball(); b.X != g.Player(Player2).X {
+		t.Fatalf("expected ball above player 2, got %+v", b)
+	}
+}
+
+func TestGame_SideOutScoring(t *testing.T) {
+	g := New(80, 24, WithRules(Rules{SideOut: true}))
+
+	landOn(g, SideLeft) // receivers win: serve only
+	if p1, p2 := g.Score(); p1 != 0 || p2 != 0 {
+		t.Fatalf("expected no point on a side-out, got %d:%d", p1, p2)
+	}
+	if g.Server() != SideRight {
+		t.Fatalf("expected the serve to pass to the right")
+	}
+
+	landOn(g, SideLeft) // servers win: point
+	if p1, p2 := g.Score(); p1 != 0 || p2 != 1 {
+		t.Fatalf("expected the serving side to score, got %d:%d", p1, p2)
+	}
+}
+
+func TestGame_PointsAndHitsSpanSets(t *testing.T) {
+	g := New(80, 24, WithRules(Rules{PointsToWin: 1, Sets: 3, MaxTouches: 3}))
+	g.Press(Player1, ActionServe)
+	bump(g, Player1)
+	bump(g, Player1)
+	landOn(g, SideRight) // set 1 to player 1
+	landOn(g, SideLeft)  // set 2 to player 2
+
+	if p1, p2 := g.Points(); p1 != 1 || p2 != 1 {
+		t.Fatalf("expected one point each over both sets, got %d:%d", p1, p2)
+	}
+	if h1, h2 := g.Hits(); h1 != 2 || h2 != 0 {
+		t.Fatalf("expected player 1's two touches counted, got %d:%d", h1, h2)
+	}
+	g.Reset()
+	if p1, p2 := g.Points(); p1+p2 != 0 {
+		t.Fatalf("expected Reset to clear the points, got %d:%d", p1, p2)
+	}
+}
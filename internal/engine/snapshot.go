@@ -0,0 +1,169 @@
+package engine
+
+import "fmt"
+
+// Snapshot is the complete state of a match in progress. Restoring it into
+// a game with the same team size, tick rate, physics and rules continues
+// the match exactly as the original would, so it can be sent to a remote
+// screen or kept to rewind the simulation.
+type Snapshot struct {
+	Tick         int              `json:"tick"`
+	WaitingServe bool             `json:"waitingServe"`
+	Server       Side             `json:"server"`
+	Serving      int              `json:"serving"`
+	NextServer   [2]int           `json:"nextServer"`
+	Sides        [2]Side          `json:"sides"`
+	Players      []PlayerSnapshot `json:"players"`
+	Ball         BallState        `json:"ball"`
+	Touches      [2]int           `json:"touches"`
+	BallSide     Side             `json:"ballSide"`
+	Score        [2]int           `json:"score"`
+	Sets         [2]int           `json:"sets"`
+	Points       [2]int           `json:"points"`
+	Hits         [2]int           `json:"hits"`
+	Over         bool             `json:"over"`
+	Winner       int              `json:"winner"`
+}
+
+// PlayerSnapshot is the complete state of one blob, including the keys it
+// holds and its jump timers.
+type PlayerSnapshot struct {
+	Team     int     `json:"team"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	VX       float64 `json:"vx"`
+	VY       float64 `json:"vy"`
+	PrevX    float64 `json:"prevX"`
+	OnGround bool    `json:"onGround"`
+
+	Left    bool    `json:"left"`
+	Right   bool    `json:"right"`
+	Jump    bool    `json:"jump"`
+	JumpReq bool    `json:"jumpReq"`
+	JumpAge float64 `json:"jumpAge"`
+	AirTime float64 `json:"airTime"`
+	Jumped  bool    `json:"jumped"`
+	Rising  bool    `json:"rising"`
+
+	Touching bool    `json:"touching"`
+	FromX    float64 `json:"fromX"`
+	FromY    float64 `json:"fromY"`
+}
+
+// Snapshot returns the state of the match.
+func (g *Game) Snapshot() Snapshot {
+	s := Snapshot{
+		Tick:         g.ticks,
+		WaitingServe: g.waitingServe,
+		Server:       g.server,
+		Serving:      g.serving,
+		NextServer:   g.nextServer,
+		Sides:        g.sides,
+		Players:      make([]PlayerSnapshot, len(g.players)),
+		Ball:         g.Ball(),
+		Touches:      g.touches,
+		BallSide:     g.ballSide,
+		Score:        g.score,
+		Sets:         g.sets,
+		Points:       g.points,
+		Hits:         g.hits,
+		Over:         g.over,
+		Winner:       g.winner,
+	}
+	for i, p := range g.players {
+		s.Players[i] = PlayerSnapshot{
+			Team: p.team,
+			X:    p.x, Y: p.y,
+			VX: p.vx, VY: p.vy,
+			PrevX:    p.prevX,
+			OnGround: p.onGround,
+			Left:     p.leftHeld,
+			Right:    p.rightHeld,
+			Jump:     p.jumpHeld,
+			JumpReq:  p.jumpReq,
+			JumpAge:  p.jumpAge,
+			AirTime:  p.airTime,
+			Jumped:   p.jumped,
+			Rising:   p.rising,
+			Touching: p.touching,
+			FromX:    p.fromX, FromY: p.fromY,
+		}
+	}
+	return s
+}
+
+// Restore puts the match back into state s. The arena, physics and rules
+// are kept; s must come from a game with as many players.
+func (g *Game) Restore(s Snapshot) error {
+	if len(s.Players) != len(g.players) {
+		return fmt.Errorf("restore: snapshot has %d players, game has %d", len(s.Players), len(g.players))
+	}
+	if err := s.check(); err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	g.ticks = s.Tick
+	g.waitingServe = s.WaitingServe
+	g.server = s.Server
+	g.serving = s.Serving
+	g.nextServer = s.NextServer
+	g.sides = s.Sides
+	g.bx, g.by, g.vx, g.vy = s.Ball.X, s.Ball.Y, s.Ball.VX, s.Ball.VY
+	g.touches = s.Touches
+	g.ballSide = s.BallSide
+	g.score = s.Score
+	g.sets = s.Sets
+	g.points = s.Points
+	g.hits = s.Hits
+	g.over = s.Over
+	g.winner = s.Winner
+	for i, p := range s.Players {
+		g.players[i] = player{
+			team: p.Team,
+			x:    p.X, y: p.Y,
+			vx: p.VX, vy: p.VY,
+			prevX:     p.PrevX,
+			onGround:  p.OnGround,
+			leftHeld:  p.Left,
+			rightHeld: p.Right,
+			jumpHeld:  p.Jump,
+			jumpReq:   p.JumpReq,
+			jumpAge:   p.JumpAge,
+			airTime:   p.AirTime,
+			jumped:    p.Jumped,
+			rising:    p.Rising,
+			touching:  p.Touching,
+			fromX:     p.FromX, fromY: p.FromY,
+		}
+	}
+	return nil
+}
+
+// check rejects a snapshot whose teams, sides or players are out of
+// range, which a game restored from it would index out of bounds. The
+// player count has been checked.
+func (s Snapshot) check() error {
+	side := func(sd Side) bool { return sd == SideLeft || sd == SideRight }
+	if !side(s.Sides[Team1]) || !side(s.Sides[Team2]) || s.Sides[Team1] == s.Sides[Team2] {
+		return fmt.Errorf("bad sides %v", s.Sides)
+	}
+	if !side(s.Server) || !side(s.BallSide) {
+		return fmt.Errorf("bad server side %d or ball side %d", s.Server, s.BallSide)
+	}
+	if s.Serving < 0 || s.Serving >= len(s.Players) {
+		return fmt.Errorf("bad serving player %d", s.Serving)
+	}
+	for t, n := range s.NextServer {
+		if n < 0 || n >= len(s.Players)/2 {
+			return fmt.Errorf("bad next server %d for team %d", n, t+1)
+		}
+	}
+	if s.Winner < -1 || s.Winner > Team2 {
+		return fmt.Errorf("bad winner %d", s.Winner)
+	}
+	for i, p := range s.Players {
+		if p.Team != i%2 {
+			return fmt.Errorf("player %d plays for team %d", i+1, p.Team+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// randomInputs returns n ticks of inputs for every player of g, with keys
+// held for a while like a person would.
+func randomInputs(g *Game, n int, seed int64) [][]Input {
+	rng := rand.New(rand.NewSource(seed))
+	ins := make([][]Input, n)
+	cur := make([]Input, g.NumPlayers())
+	for t := range ins {
+		for i := range cur {
+			if rng.Intn(20) == 0 {
+				cur[i] = Input{Left: rng.Intn(2) == 0, Right: rng.Intn(2) == 0, Jump: rng.Intn(3) == 0}
+			}
+		}
+		ins[t] = append([]Input(nil), cur...)
+		ins[t][t%len(cur)].Serve = rng.Intn(50) == 0
+	}
+	return ins
+}
+
+func play(g *Game, ins [][]Input) {
+	for _, tick := range ins {
+		for i, in := range tick {
+			g.SetInput(i, in)
+		}
+		g.Step()
+	}
+}
+
+func TestGame_RestoreReplaysExactly(t *testing.T) {
+	newGame := func() *Game { return New(80, 24, WithTeamSize(2)) }
+	g := newGame()
+	ins := randomInputs(g, 6000, 1)
+	play(g, ins[:3000])
+	saved := g.Snapshot()
+	play(g, ins[3000:])
+	want := g.Snapshot()
+
+	// Through JSON, as a snapshot travels over the network.
+	data, err := json.Marshal(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	r := newGame()
+	if err := r.Restore(s); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Snapshot(); !reflect.DeepEqual(got, saved) {
+		t.Fatalf("restored state differs:\n got %+v\nwant %+v", got, saved)
+	}
+	play(r, ins[3000:])
+	if got := r.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("replay from the snapshot diverged:\n got %+v\nwant %+v", got, want)
+	}
+	if p1, p2 := r.Points(); p1+p2 == 0 {
+		t.Fatalf("expected points to be scored in the replay")
+	}
+}
+
+func TestGame_RestoreRejectsOtherTeamSize(t *testing.T) {
+	s := New(80, 24, WithTeamSize(2)).Snapshot()
+	g := New(80, 24)
+	before := g.Snapshot()
+	if err := g.Restore(s); err == nil {
+		t.Fatalf("expected an error restoring 4 players into a duel")
+	}
+	if !reflect.DeepEqual(g.Snapshot(), before) {
+		t.Fatalf("a rejected restore changed the game")
+	}
+}
+
+func TestGame_RestoreRejectsOutOfRange(t *testing.T) {
+	g := New(80, 24, WithTeamSize(2))
+	for name, bad := range map[string]func(*Snapshot){
+		"team":        func(s *Snapshot) { s.Players[1].Team = 7 },
+		"sides":       func(s *Snapshot) { s.Sides = [2]Side{SideLeft, 5} },
+		"same sides":  func(s *Snapshot) { s.Sides = [2]Side{SideRight, SideRight} },
+		"serving":     func(s *Snapshot) { s.Serving = 4 },
+		"next server": func(s *Snapshot) { s.NextServer[1] = 2 },
+		"server":      func(s *Snapshot) { s.Server = 2 },
+		"ball side":   func(s *Snapshot) { s.BallSide = 9 },
+		"winner":      func(s *Snapshot) { s.Winner = 2 },
+	} {
+		s := g.Snapshot()
+		bad(&s)
+		before := g.Snapshot()
+		if err := g.Restore(s); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if !reflect.DeepEqual(g.Snapshot(), before) {
+			t.Errorf("%s: a rejected restore changed the game", name)
+		}
+	}
+}
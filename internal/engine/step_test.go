@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var tickRates = []int{MinTickRate, 60, 120, DefaultTickRate, 500, MaxTickRate}
+
+// overlap describes how the ball overlaps a blob, the net or a wall, or
+// returns "" when it is clear of all of them.
+func overlap(g *Game) string {
+	const eps = 1e-6
+	r := g.phys.BallRadius
+	if g.bx < 1+r-eps || g.bx > float64(g.W-2)-r+eps {
+		return "side wall"
+	}
+	if g.by < 1+r-eps {
+		return "ceiling"
+	}
+	cy := math.Max(float64(g.netTopY), math.Min(g.by, float64(g.netBottomY)))
+	if math.Hypot(g.bx-float64(g.netX), g.by-cy) < netRadius+r-eps {
+		return "net"
+	}
+	for i := range g.players {
+		cx, cy := g.players[i].centerAt(1)
+		if math.Hypot(g.bx-cx, g.by-cy) < g.phys.BlobRadius+r-eps {
+			return "blob"
+		}
+	}
+	return ""
+}
+
+func TestStep_BallNeverEndsATickOverlapping(t *testing.T) {
+	actions := []Action{ActionLeft, ActionRight, ActionJump}
+	for _, hz := range tickRates {
+		rng := rand.New(rand.NewSource(int64(hz)))
+		for round := 0; round < 200; round++ {
+			g := New(80, 24, WithTickRate(hz))
+			g.Press(Player1, ActionServe)
+			g.bx = 3 + rng.Float64()*74
+			g.by = 3 + rng.Float64()*10
+			g.vx = (rng.Float64()*2 - 1) * 80
+			g.vy = (rng.Float64()*2 - 1) * 80
+
+			for tick := 0; tick < hz && !g.WaitingServe(); tick++ {
+				for i := range g.players {
+					a := actions[rng.Intn(len(actions))]
+					if rng.Intn(2) == 0 {
+						g.Press(i, a)
+					} else {
+						g.Release(i, a)
+					}
+				}
+				g.Step()
+				if g.WaitingServe() {
+					break
+				}
+				if what := overlap(g); what != "" {
+					t.Fatalf("%d Hz round %d tick %d: ball overlaps the %s at (%.3f, %.3f)",
+						hz, round, tick, what, g.bx, g.by)
+				}
+			}
+		}
+	}
+}
+
+func TestStep_FastBallCannotTunnelThroughBlob(t *testing.T) {
+	for _, hz := range tickRates {
+		g := New(80, 24, WithTickRate(hz))
+		g.Press(Player1, ActionServe)
+		p := g.Player(Player1)
+
+		// Level with the blob's center, 8 cells to its left, at a speed
+		// that crosses the whole blob within one tick at low rates.
+		g.bx, g.by = p.X-8, p.Y-0.5
+		g.vx, g.vy = 150, 0
+		g.Step()
+		g.Step()
+		if g.bx > p.X {
+			t.Fatalf("%d Hz: ball passed through the blob to x=%.2f", hz, g.bx)
+		}
+	}
+}
+
+func TestStep_FastBallCannotTunnelThroughNet(t *testing.T) {
+	for _, hz := range tickRates {
+		g := New(80, 24, WithTickRate(hz))
+		g.Press(Player1, ActionServe)
+		g.bx, g.by = float64(g.netX)-3, float64(g.netTopY+3)
+		g.vx, g.vy = 200, 0
+		g.Step()
+		if g.bx > float64(g.netX) {
+			t.Fatalf("%d Hz: ball passed through the net to x=%.2f", hz, g.bx)
+		}
+	}
+}
+
+func TestWithTickRate_Clamps(t *testing.T) {
+	if g := New(80, 24, WithTickRate(1)); g.dt != 1.0/MinTickRate {
+		t.Fatalf("expected %d Hz minimum, dt=%v", MinTickRate, g.dt)
+	}
+	if g := New(80, 24, WithTickRate(1e6)); g.dt != 1.0/MaxTickRate {
+		t.Fatalf("expected %d Hz maximum, dt=%v", MaxTickRate, g.dt)
+	}
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTeams_DoublesSetup(t *testing.T) {
+	g := New(80, 24, WithTeamSize(2))
+	if g.NumPlayers() != 4 || g.TeamSize() != 2 {
+		t.Fatalf("expected 4 players in teams of 2, got %d/%d", g.NumPlayers(), g.TeamSize())
+	}
+	for i := 0; i < g.NumPlayers(); i++ {
+		p := g.Player(i)
+		if p.Team != i%2 {
+			t.Fatalf("player %d: expected team %d, got %d", i, i%2, p.Team)
+		}
+		if onLeft := p.X < float64(g.NetX()); onLeft != (p.Side == SideLeft) {
+			t.Fatalf("player %d at x=%v is not on its %v half", i, p.X, p.Side)
+		}
+	}
+	if g.Player(Player1).X == g.Player(Player3).X {
+		t.Fatalf("expected teammates to start apart")
+	}
+}
+
+func TestWithTeamSize_Clamps(t *testing.T) {
+	if n := New(80, 24, WithTeamSize(0)).NumPlayers(); n != 2 {
+		t.Fatalf("expected a duel for size 0, got %d players", n)
+	}
+	if n := New(80, 24, WithTeamSize(10)).NumPlayers(); n != 2*MaxTeamSize {
+		t.Fatalf("expected %d players at most, got %d", 2*MaxTeamSize, n)
+	}
+}
+
+func TestTeams_ShareTouchCounter(t *testing.T) {
+	g := New(80, 24, WithTeamSize(2), WithRules(Rules{MaxTouches: 3}))
+	g.Press(Player3, ActionServe) // a teammate tosses for the server
+
+	bump(g, Player1)
+	bump(g, Player3)
+	bump(g, Player1)
+	if got := g.Touches(SideLeft); got != 3 {
+		t.Fatalf("expected teammates to share the count, got %d", got)
+	}
+	bump(g, Player3)
+	if _, p2 := g.Score(); p2 != 1 {
+		t.Fatalf("expected the team's fourth touch to be a fault")
+	}
+}
+
+func TestTeams_ServeRotates(t *testing.T) {
+	g := New(80, 24, WithTeamSize(2))
+	if g.Serving() != Player1 {
+		t.Fatalf("expected player 1 to serve first, got %d", g.Serving())
+	}
+
+	landOn(g, SideLeft) // team 2 wins the serve
+	if g.Serving() != Player2 {
+		t.Fatalf("expected player 2 to serve for team 2, got %d", g.Serving())
+	}
+	landOn(g, SideLeft) // team 2 keeps it
+	if g.Serving() != Player2 {
+		t.Fatalf("expected the same server while the team keeps serving, got %d", g.Serving())
+	}
+	landOn(g, SideRight) // team 1 wins it back and rotates
+	if g.Serving() != Player3 {
+		t.Fatalf("expected player 3 to serve next for team 1, got %d", g.Serving())
+	}
+	if b := g.Ball(); b.X != g.Player(Player3).X {
+		t.Fatalf("expected the ball above player 3")
+	}
+	if t1, t2 := g.Score(); t1 != 1 || t2 != 2 {
+		t.Fatalf("expected team scores 1:2, got %d:%d", t1, t2)
+	}
+}
+
+func TestTeams_TeammatesDoNotOverlap(t *testing.T) {
+	g := New(80, 24, WithTeamSize(2))
+	g.Press(Player1, ActionRight)
+	g.Press(Player3, ActionLeft)
+	for i := 0; i < 400; i++ {
+		g.Step()
+	}
+	a, b := g.Player(Player1), g.Player(Player3)
+	if d := math.Abs(a.X - b.X); d < 2*g.Physics().BlobRadius-1e-9 {
+		t.Fatalf("expected teammates kept apart, distance %v", d)
+	}
+}
@@ -0,0 +1,248 @@
+// Package gym wraps a Game as a reinforcement-learning environment in the
+// style of OpenAI Gym: Reset starts an episode, Step advances it with one
+// action per player and returns what each player observes, its reward and
+// whether the episode is done. Serve exposes the same over a socket.
+package gym
+
+import "terminalvolley/internal/engine"
+
+// Action is what a player does for a step, as a set of bits; the 16
+// possible actions are the numbers 0 to 15.
+type Action uint8
+
+const (
+	ActionLeft Action = 1 << iota
+	ActionRight
+	ActionJump
+	ActionServe
+)
+
+// NumActions is the number of distinct actions.
+const NumActions = 16
+
+// Input returns the action as engine input.
+func (a Action) Input() engine.Input {
+	return engine.Input{Left: a&ActionLeft != 0, Right: a&ActionRight != 0, Jump: a&ActionJump != 0, Serve: a&ActionServe != 0}
+}
+
+// Rewards weighs what a player is rewarded for. Both go to every player
+// of the team.
+type Rewards struct {
+	// Point is earned per point the team scores and lost per point the
+	// other team scores.
+	Point float64 `json:"point"`
+	// Touch is earned per touch of the ball by the team.
+	Touch float64 `json:"touch"`
+}
+
+// DefaultRewards mostly rewards points, with a little for keeping the
+// ball in play.
+var DefaultRewards = Rewards{Point: 1, Touch: 0.1}
+
+// velScale brings velocities in cells per second to roughly -1..1 in
+// observations.
+const velScale = 50
+
+// Result is the outcome of a step.
+type Result struct {
+	// Obs and Rewards hold one entry per player.
+	Obs     [][]float64 `json:"obs"`
+	Rewards []float64   `json:"rewards"`
+	// Done is set once the match is over or MaxTicks have passed.
+	Done bool `json:"done"`
+	Tick int  `json:"tick"`
+}
+
+// Env is a match played one step at a time. It is deterministic: the
+// same seed and actions replay the same episode.
+type Env struct {
+	newGame   func() *engine.Game
+	players   map[int]func(seed int64) engine.Controller
+	rewards   Rewards
+	frameSkip int
+	maxTicks  int
+
+	g     *engine.Game
+	ctrls []engine.Controller
+}
+
+// Option configures an Env.
+type Option func(*Env)
+
+// WithGame sets how each episode's game is made; by default it is a 1v1
+// match with the default rules and physics.
+func WithGame(newGame func() *engine.Game) Option {
+	return func(e *Env) { e.newGame = newGame }
+}
+
+// WithController lets a built-in controller, such as a CPU, play player
+// i; its actions passed to Step are ignored. The controller is made anew
+// each episode from the episode's seed.
+func WithController(i int, newCtrl func(seed int64) engine.Controller) Option {
+	return func(e *Env) { e.players[i] = newCtrl }
+}
+
+// WithRewards sets the reward weights.
+func WithRewards(r Rewards) Option {
+	return func(e *Env) { e.rewards = r }
+}
+
+// WithFrameSkip makes each step hold the actions for n ticks and sum up
+// the rewards. A serve is only requested in the first.
+func WithFrameSkip(n int) Option {
+	return func(e *Env) { e.frameSkip = max(n, 1) }
+}
+
+// WithMaxTicks ends an episode after n ticks even if the match goes on;
+// 0 means no limit.
+func WithMaxTicks(n int) Option {
+	return func(e *Env) { e.maxTicks = n }
+}
+
+// New creates an environment, already reset with seed 0.
+func New(opts ...Option) *Env {
+	e := &Env{
+		newGame:   func() *engine.Game { return engine.New(80, 24) },
+		players:   map[int]func(int64) engine.Controller{},
+		rewards:   DefaultRewards,
+		frameSkip: 1,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.Reset(0)
+	return e
+}
+
+// Reset starts a new episode and returns each player's observation. The
+// seed only seeds the controllers given WithController: the match itself
+// has no randomness, so without one every seed starts the same episode.
+func (e *Env) Reset(seed int64) [][]float64 {
+	e.g = e.newGame()
+	e.ctrls = make([]engine.Controller, e.g.NumPlayers())
+	for i, newCtrl := range e.players {
+		if i >= 0 && i < len(e.ctrls) {
+			e.ctrls[i] = newCtrl(seed + int64(i))
+		}
+	}
+	return e.observeAll()
+}
+
+// Step plays the next step with an action per player; missing actions
+// count as 0.
+func (e *Env) Step(actions []Action) Result {
+	g := e.g
+	rewards := make([]float64, g.NumPlayers())
+	for k := 0; k < e.frameSkip && !e.done(); k++ {
+		p1, p2 := g.Points()
+		h1, h2 := g.Hits()
+		for i, c := range e.ctrls {
+			var in engine.Input
+			switch {
+			case c != nil:
+				in = c.Control(g, i)
+			case i < len(actions):
+				in = actions[i].Input()
+				in.Serve = in.Serve && k == 0
+			}
+			g.SetInput(i, in)
+		}
+		g.Step()
+
+		q1, q2 := g.Points()
+		k1, k2 := g.Hits()
+		points := [2]float64{float64(q1 - p1), float64(q2 - p2)}
+		hits := [2]float64{float64(k1 - h1), float64(k2 - h2)}
+		for i := range rewards {
+			t := g.Player(i).Team
+			rewards[i] += e.rewards.Point*(points[t]-points[1-t]) + e.rewards.Touch*hits[t]
+		}
+	}
+	return Result{Obs: e.observeAll(), Rewards: rewards, Done: e.done(), Tick: g.Ticks()}
+}
+
+func (e *Env) done() bool {
+	return e.g.Over() || (e.maxTicks > 0 && e.g.Ticks() >= e.maxTicks)
+}
+
+// Game returns the game of the current episode, for drawing or
+// inspection. Changing it changes the episode.
+func (e *Env) Game() *engine.Game { return e.g }
+
+// NumPlayers returns how many players act in each step.
+func (e *Env) NumPlayers() int { return e.g.NumPlayers() }
+
+// Controlled reports whether player i is played by a built-in controller.
+func (e *Env) Controlled(i int) bool { return i >= 0 && i < len(e.ctrls) && e.ctrls[i] != nil }
+
+// ObsLen returns the length of an observation.
+func (e *Env) ObsLen() int { return 11 + 10*e.g.TeamSize() }
+
+func (e *Env) observeAll() [][]float64 {
+	obs := make([][]float64, e.g.NumPlayers())
+	for i := range obs {
+		obs[i] = e.Observe(i)
+	}
+	return obs
+}
+
+// Observe returns what player i sees, mirrored so that its own half is
+// always the left one. Positions are scaled to 0..1 of the arena and
+// velocities to about -1..1. In order:
+//
+//	ball x, y, vx, vy
+//	x, y, vx, vy, on ground of player i, its teammates, then opponents
+//	waiting for serve, i serves, i's team serves
+//	touches of i's side and of the other side
+//	score of i's team and of the other team in the current set
+func (e *Env) Observe(i int) []float64 {
+	g := e.g
+	me := g.Player(i)
+	w, h := float64(g.W), float64(g.H)
+	flip := me.Side == engine.SideRight
+	x := func(v float64) float64 {
+		if flip {
+			return (w - v) / w
+		}
+		return v / w
+	}
+	vx := func(v float64) float64 {
+		if flip {
+			return -v / velScale
+		}
+		return v / velScale
+	}
+	flag := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+
+	obs := make([]float64, 0, e.ObsLen())
+	b := g.Ball()
+	obs = append(obs, x(b.X), b.Y/h, vx(b.VX), b.VY/velScale)
+	add := func(p engine.PlayerState) {
+		obs = append(obs, x(p.X), p.Y/h, vx(p.VX), p.VY/velScale, flag(p.OnGround))
+	}
+	add(me)
+	for _, mates := range []bool{true, false} {
+		for j := 0; j < g.NumPlayers(); j++ {
+			if p := g.Player(j); j != i && (p.Team == me.Team) == mates {
+				add(p)
+			}
+		}
+	}
+
+	serving := g.Player(g.Serving())
+	obs = append(obs, flag(g.WaitingServe()), flag(g.Serving() == i), flag(serving.Team == me.Team))
+	touches := func(s engine.Side) float64 {
+		return float64(g.Touches(s)) / float64(max(g.Rules().MaxTouches, 3))
+	}
+	obs = append(obs, touches(me.Side), touches(me.Side.Other()))
+	s1, s2 := g.Score()
+	score := [2]float64{float64(s1), float64(s2)}
+	norm := float64(max(g.Rules().PointsToWin, 1))
+	obs = append(obs, score[me.Team]/norm, score[1-me.Team]/norm)
+	return obs
+}
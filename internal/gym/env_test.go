@@ -0,0 +1,86 @@
+package gym
+
+import (
+	"reflect"
+	"testing"
+
+	"terminalvolley/internal/ai"
+	"terminalvolley/internal/engine"
+)
+
+func cpuOpponent(seed int64) engine.Controller {
+	d, _ := ai.Level("normal")
+	return ai.NewCPU(ai.WithDifficulty(d), ai.WithSeed(seed))
+}
+
+func TestEnv_ObservationsAreMirrored(t *testing.T) {
+	e := New()
+	obs := e.Reset(1)
+	if len(obs) != 2 || len(obs[0]) != e.ObsLen() || len(obs[1]) != e.ObsLen() {
+		t.Fatalf("unexpected observation shape %d, %d, want %d", len(obs[0]), len(obs[1]), e.ObsLen())
+	}
+	// Both blobs start in the same spot of their own half; only the serve
+	// and ball flags differ.
+	for k := 4; k < 14; k++ {
+		if obs[0][k] != obs[1][k] {
+			t.Fatalf("obs[%d]: player 1 sees %v, player 2 sees %v", k, obs[0][k], obs[1][k])
+		}
+	}
+	if obs[0][15] != 1 || obs[1][15] != 0 {
+		t.Fatalf("expected only player 1 to serve, got %v and %v", obs[0][15], obs[1][15])
+	}
+}
+
+func TestEnv_RewardsPoints(t *testing.T) {
+	e := New(WithController(engine.Player2, cpuOpponent))
+	var got [2]float64
+	for i := 0; i < 200*60; i++ {
+		// Player 1 tosses its serve and walks away from it.
+		r := e.Step([]Action{ActionServe | ActionLeft})
+		got[0] += r.Rewards[0]
+		got[1] += r.Rewards[1]
+		if p1, p2 := e.Game().Points(); p1+p2 > 0 {
+			break
+		}
+	}
+	if got[0] != -1 || got[1] != 1 {
+		t.Fatalf("expected the CPU's point to reward it and punish the idle player, got %v", got)
+	}
+}
+
+func TestEnv_SameSeedSameEpisode(t *testing.T) {
+	run := func(seed int64) [][]float64 {
+		e := New(WithController(engine.Player2, cpuOpponent), WithFrameSkip(4))
+		e.Reset(seed)
+		var r Result
+		for i := 0; i < 500; i++ {
+			r = e.Step([]Action{Action(i % NumActions)})
+		}
+		return r.Obs
+	}
+	if !reflect.DeepEqual(run(7), run(7)) {
+		t.Fatalf("expected the same seed and actions to replay the same episode")
+	}
+}
+
+func TestEnv_DoneAtMaxTicks(t *testing.T) {
+	e := New(WithFrameSkip(3), WithMaxTicks(10))
+	var r Result
+	for i := 0; i < 4; i++ {
+		r = e.Step(nil)
+	}
+	if !r.Done || r.Tick != 10 {
+		t.Fatalf("expected done at tick 10, got done=%v tick %d", r.Done, r.Tick)
+	}
+	e.Reset(0)
+	if r := e.Step(nil); r.Done || r.Tick != 3 {
+		t.Fatalf("expected a fresh episode after reset, got done=%v tick %d", r.Done, r.Tick)
+	}
+}
+
+func TestAction_Input(t *testing.T) {
+	in := (ActionLeft | ActionJump).Input()
+	if !in.Left || in.Right || !in.Jump || in.Serve {
+		t.Fatalf("unexpected input %+v", in)
+	}
+}
@@ -0,0 +1,101 @@
+package gym
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Request is a line a client sends to a served environment. Cmd is
+// "spec", "reset" (with Seed) or "step" (with Actions).
+type Request struct {
+	Cmd     string   `json:"cmd"`
+	Seed    int64    `json:"seed"`
+	Actions []Action `json:"actions"`
+}
+
+// Spec describes a served environment.
+type Spec struct {
+	Players    int    `json:"players"`
+	ObsLen     int    `json:"obsLen"`
+	Actions    int    `json:"actions"`
+	Controlled []bool `json:"controlled"`
+}
+
+// response is the reply to a Request: a Spec, a Result holding the
+// observations after a reset or everything after a step, or an error.
+type response struct {
+	*Spec
+	*Result
+	Error string `json:"error,omitempty"`
+}
+
+// Serve accepts connections on l and gives each its own environment from
+// newEnv. Clients send one JSON Request per line and read one JSON reply
+// per line. Serve returns when l is closed.
+func Serve(l net.Listener, newEnv func() *Env) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go serveConn(conn, newEnv())
+	}
+}
+
+func serveConn(conn net.Conn, e *Env) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	enc := json.NewEncoder(w)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		var req Request
+		var resp response
+		if err := json.Unmarshal(line, &req); err != nil {
+			resp.Error = fmt.Sprintf("bad request: %v", err)
+		} else {
+			resp = handle(e, req)
+		}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+		// Flush only once the client has sent everything it pipelined.
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func handle(e *Env, req Request) response {
+	switch req.Cmd {
+	case "spec":
+		s := Spec{Players: e.NumPlayers(), ObsLen: e.ObsLen(), Actions: NumActions}
+		for i := 0; i < e.NumPlayers(); i++ {
+			s.Controlled = append(s.Controlled, e.Controlled(i))
+		}
+		return response{Spec: &s}
+	case "reset":
+		obs := e.Reset(req.Seed)
+		return response{Result: &Result{Obs: obs, Tick: e.Game().Ticks()}}
+	case "step":
+		for _, a := range req.Actions {
+			if a >= NumActions {
+				return response{Error: fmt.Sprintf("action %d out of range 0..%d", a, NumActions-1)}
+			}
+		}
+		r := e.Step(req.Actions)
+		return response{Result: &r}
+	}
+	return response{Error: fmt.Sprintf("unknown cmd %q (want spec, reset or step)", req.Cmd)}
+}
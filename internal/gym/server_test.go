@@ -0,0 +1,85 @@
+package gym
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"terminalvolley/internal/engine"
+)
+
+// dial serves a fresh environment per connection on network and returns
+// a client connection.
+func dial(t *testing.T, network, addr string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go Serve(l, func() *Env { return New(WithController(engine.Player2, cpuOpponent)) })
+
+	conn, err := net.Dial(network, l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, bufio.NewReader(conn)
+}
+
+// call sends req and decodes the reply into v.
+func call(t *testing.T, conn net.Conn, r *bufio.Reader, req string, v any) {
+	t.Helper()
+	if _, err := fmt.Fprintln(conn, req); err != nil {
+		t.Fatal(err)
+	}
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(line, v); err != nil {
+		t.Fatalf("bad reply %q: %v", line, err)
+	}
+}
+
+func TestServe_Protocol(t *testing.T) {
+	for _, network := range []string{"tcp", "unix"} {
+		addr := "127.0.0.1:0"
+		if network == "unix" {
+			addr = filepath.Join(t.TempDir(), "gym.sock")
+		}
+		conn, r := dial(t, network, addr)
+
+		var spec Spec
+		call(t, conn, r, `{"cmd":"spec"}`, &spec)
+		if spec.Players != 2 || spec.ObsLen != 21 || spec.Actions != NumActions || !slices.Equal(spec.Controlled, []bool{false, true}) {
+			t.Fatalf("%s: unexpected spec %+v", network, spec)
+		}
+
+		var res Result
+		call(t, conn, r, `{"cmd":"reset","seed":3}`, &res)
+		if len(res.Obs) != 2 || len(res.Obs[0]) != spec.ObsLen || res.Tick != 0 {
+			t.Fatalf("%s: unexpected reset %+v", network, res)
+		}
+		for i := 1; i <= 3; i++ {
+			call(t, conn, r, `{"cmd":"step","actions":[10]}`, &res)
+			if res.Tick != i || len(res.Rewards) != 2 || res.Done {
+				t.Fatalf("%s: unexpected step %+v", network, res)
+			}
+		}
+
+		var bad struct{ Error string }
+		call(t, conn, r, `{"cmd":"step","actions":[16]}`, &bad)
+		if bad.Error == "" {
+			t.Fatalf("%s: expected an error for an invalid action", network)
+		}
+		call(t, conn, r, `{"cmd":"fly"}`, &bad)
+		if bad.Error == "" {
+			t.Fatalf("%s: expected an error for an unknown command", network)
+		}
+	}
+}
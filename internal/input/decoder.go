@@ -0,0 +1,287 @@
+package input
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const esc = 0x1b
+
+// Decoder turns the raw byte stream of a terminal into key events. It
+// understands plain (UTF-8) characters, control and Alt-prefixed keys, the
+// xterm/VT escape sequences for arrow, navigation and function keys, and
+// the kitty keyboard protocol's CSI ... u reports. Unrecognized sequences
+// are swallowed so they cannot trigger bindings by accident.
+type Decoder struct {
+	pending []byte
+}
+
+// Feed decodes as many complete events as possible from the bytes seen so
+// far. An incomplete trailing sequence is kept for the next call.
+func (d *Decoder) Feed(p []byte) []Event {
+	d.pending = append(d.pending, p...)
+
+	var out []Event
+	for len(d.pending) > 0 {
+		ev, n, ok := decodeOne(d.pending)
+		if n == 0 {
+			break
+		}
+		d.pending = d.pending[n:]
+		if ok {
+			out = append(out, ev)
+		}
+	}
+	if len(d.pending) == 0 {
+		d.pending = nil
+	}
+	return out
+}
+
+// Pending reports whether an incomplete sequence is waiting for more bytes.
+func (d *Decoder) Pending() bool { return len(d.pending) > 0 }
+
+// Flush gives up on an incomplete sequence, so a bare Escape is not held
+// back forever. Anything that began with Escape, such as a control sequence
+// cut short, reports a single Escape; its other bytes are dropped rather
+// than read as keys. A partial UTF-8 character is dropped.
+func (d *Decoder) Flush() []Event {
+	var out []Event
+	if len(d.pending) > 0 && d.pending[0] == esc {
+		out = append(out, Event{Key: KeyEscape, Type: KeyPress})
+	}
+	d.pending = nil
+	return out
+}
+
+// decodeOne decodes the event at the start of b. n is the number of bytes
+// consumed (0 if b holds an incomplete sequence) and ok is false for input
+// that was consumed without producing an event.
+func decodeOne(b []byte) (ev Event, n int, ok bool) {
+	if b[0] != esc {
+		ev, n := decodeChar(b)
+		return ev, n, n > 0
+	}
+
+	if len(b) < 2 {
+		return Event{}, 0, false
+	}
+	switch b[1] {
+	case '[':
+		params, final, n := scanCSI(b)
+		if n <= 0 {
+			if n < 0 {
+				// Malformed: report the Escape and resync on the next byte.
+				return Event{Key: KeyEscape, Type: KeyPress}, 1, true
+			}
+			return Event{}, 0, false
+		}
+		ev, ok := parseCSI(params, final)
+		return ev, n, ok
+	case 'O':
+		// SS3: application-mode arrows and F1-F4.
+		if len(b) < 3 {
+			return Event{}, 0, false
+		}
+		k, ok := letterKeys[b[2]]
+		return Event{Key: k, Type: KeyPress}, 3, ok
+	case esc:
+		return Event{Key: KeyEscape, Type: KeyPress}, 1, true
+	}
+
+	// ESC followed by a character is how terminals send Alt+key.
+	ev, n = decodeChar(b[1:])
+	if n == 0 {
+		return Event{}, 0, false
+	}
+	ev.Mods |= ModAlt
+	return ev, n + 1, true
+}
+
+// decodeChar decodes one UTF-8 character or control byte, returning n=0 if
+// b holds an incomplete rune.
+func decodeChar(b []byte) (Event, int) {
+	switch c := b[0]; {
+	case c == 0:
+		return Event{Key: KeySpace, Type: KeyPress, Mods: ModCtrl}, 1
+	case c == '\n':
+		return Event{Key: KeyEnter, Type: KeyPress}, 1
+	case c < 0x20 && c != '\t' && c != '\r' && c != esc:
+		return Event{Key: Key('a' + c - 1), Type: KeyPress, Mods: ModCtrl}, 1
+	}
+	if !utf8.FullRune(b) {
+		return Event{}, 0
+	}
+	r, size := utf8.DecodeRune(b)
+	return Event{Key: Key(r), Type: KeyPress}, size
+}
+
+// letterKeys maps the final byte of SS3 sequences (ESC O A) and of CSI
+// sequences such as CSI A or CSI 1;5A.
+var letterKeys = map[byte]Key{
+	'A': KeyArrowUp,
+	'B': KeyArrowDown,
+	'C': KeyArrowRight,
+	'D': KeyArrowLeft,
+	'H': KeyHome,
+	'F': KeyEnd,
+	'P': KeyF1,
+	'Q': KeyF2,
+	'R': KeyF3,
+	'S': KeyF4,
+}
+
+// csiTildeKeys maps the first parameter of CSI n ~ sequences.
+var csiTildeKeys = map[int]Key{
+	1:  KeyHome,
+	2:  KeyInsert,
+	3:  KeyDelete,
+	4:  KeyEnd,
+	5:  KeyPageUp,
+	6:  KeyPageDown,
+	7:  KeyHome,
+	8:  KeyEnd,
+	11: KeyF1,
+	12: KeyF2,
+	13: KeyF3,
+	14: KeyF4,
+	15: KeyF5,
+	17: KeyF6,
+	18: KeyF7,
+	19: KeyF8,
+	20: KeyF9,
+	21: KeyF10,
+	23: KeyF11,
+	24: KeyF12,
+}
+
+// parseCSI interprets a complete control sequence.
+func parseCSI(params string, final byte) (Event, bool) {
+	switch final {
+	case 'u':
+		return parseKittyKey(params)
+	case '~':
+		if strings.HasPrefix(params, "?") {
+			return Event{}, false
+		}
+		fields := strings.Split(params, ";")
+		n, err := strconv.Atoi(firstSub(fields[0]))
+		if err != nil {
+			return Event{}, false
+		}
+		k, ok := csiTildeKeys[n]
+		if !ok {
+			return Event{}, false
+		}
+		ev := Event{Key: k, Type: KeyPress}
+		if len(fields) > 1 {
+			ev.Mods, ev.Type = parseModifiers(fields[1])
+		}
+		return ev, true
+	case 'Z':
+		// Back-tab.
+		return Event{Key: KeyTab, Type: KeyPress, Mods: ModShift}, params == ""
+	}
+
+	k, ok := letterKeys[final]
+	if !ok || strings.HasPrefix(params, "?") {
+		return Event{}, false
+	}
+	ev := Event{Key: k, Type: KeyPress}
+	// Modified keys arrive as CSI 1;mods[:event] x.
+	if fields := strings.Split(params, ";"); len(fields) > 1 {
+		ev.Mods, ev.Type = parseModifiers(fields[1])
+	}
+	return ev, true
+}
+
+// scanCSI finds the end of the control sequence starting at b (which begins
+// with ESC [). It returns the parameter bytes, the final byte and the total
+// length; n is 0 if the sequence is incomplete and -1 if it is malformed.
+func scanCSI(b []byte) (params string, final byte, n int) {
+	i := 2
+	for i < len(b) && b[i] >= 0x30 && b[i] <= 0x3f {
+		i++
+	}
+	p := i
+	for i < len(b) && b[i] >= 0x20 && b[i] <= 0x2f {
+		i++
+	}
+	if i >= len(b) {
+		return "", 0, 0
+	}
+	if b[i] < 0x40 || b[i] > 0x7e {
+		return "", 0, -1
+	}
+	return string(b[2:p]), b[i], i + 1
+}
+
+// parseKittyKey parses the parameters of a kitty keyboard protocol report:
+// code[:alternates];modifiers[:event];text. Replies to the capability query
+// (CSI ? flags u) are not key events.
+func parseKittyKey(params string) (Event, bool) {
+	if strings.HasPrefix(params, "?") {
+		return Event{}, false
+	}
+	fields := strings.Split(params, ";")
+
+	code, err := strconv.Atoi(firstSub(fields[0]))
+	if err != nil || code <= 0 {
+		return Event{}, false
+	}
+	ev := Event{Key: Key(code), Type: KeyPress}
+	if k, ok := kittyFunctionalKeys[code]; ok {
+		ev.Key = k
+	}
+
+	if len(fields) > 1 {
+		ev.Mods, ev.Type = parseModifiers(fields[1])
+	}
+	return ev, true
+}
+
+// kittyFunctionalKeys maps the private-use code points the kitty protocol
+// reports for the keypad's navigation keys onto their main-block keys.
+var kittyFunctionalKeys = map[int]Key{
+	57417: KeyArrowLeft,
+	57418: KeyArrowRight,
+	57419: KeyArrowUp,
+	57420: KeyArrowDown,
+	57421: KeyPageUp,
+	57422: KeyPageDown,
+	57423: KeyHome,
+	57424: KeyEnd,
+	57425: KeyInsert,
+	57426: KeyDelete,
+}
+
+// parseModifiers decodes the "modifiers[:event]" parameter shared by xterm
+// and the kitty protocol, where modifiers is 1 plus a bit set and event is
+// 1 (press), 2 (repeat) or 3 (release).
+func parseModifiers(field string) (Mod, EventType) {
+	sub := strings.Split(field, ":")
+
+	var mods Mod
+	if m, err := strconv.Atoi(sub[0]); err == nil && m > 1 {
+		mods = Mod(m-1) & (ModShift | ModAlt | ModCtrl | ModSuper)
+	}
+
+	typ := KeyPress
+	if len(sub) > 1 {
+		switch sub[1] {
+		case "2":
+			typ = KeyRepeat
+		case "3":
+			typ = KeyRelease
+		}
+	}
+	return mods, typ
+}
+
+func firstSub(field string) string {
+	if i := strings.IndexByte(field, ':'); i >= 0 {
+		return field[:i]
+	}
+	return field
+}
@@ -0,0 +1,199 @@
+package input
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecoder_PlainBytesArePresses(t *testing.T) {
+	var d Decoder
+	got := d.Feed([]byte("aW"))
+	want := []Event{{Key: 'a', Type: KeyPress}, {Key: 'W', Type: KeyPress}}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("event %d: got %+v want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDecoder_UTF8SplitAcrossFeeds(t *testing.T) {
+	var d Decoder
+	b := []byte("é")
+	if got := d.Feed(b[:1]); len(got) != 0 {
+		t.Fatalf("expected partial rune to wait, got %+v", got)
+	}
+	got := d.Feed(b[1:])
+	if len(got) != 1 || got[0].Key != 'é' {
+		t.Fatalf("expected é, got %+v", got)
+	}
+}
+
+func TestDecoder_KittyPressRepeatRelease(t *testing.T) {
+	var d Decoder
+	got := d.Feed([]byte("\x1b[97u\x1b[97;1:2u\x1b[97;1:3u"))
+	want := []EventType{KeyPress, KeyRepeat, KeyRelease}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i, typ := range want {
+		if got[i].Key != 'a' || got[i].Type != typ {
+			t.Fatalf("event %d: got %+v want key a type %d", i, got[i], typ)
+		}
+	}
+}
+
+func TestDecoder_KittyModifiersAndAlternates(t *testing.T) {
+	var d Decoder
+	got := d.Feed([]byte("\x1b[97:65;6u"))
+	if len(got) != 1 {
+		t.Fatalf("got %+v", got)
+	}
+	if got[0].Key != 'a' || got[0].Mods != ModShift|ModCtrl {
+		t.Fatalf("expected ctrl+shift+a, got %+v", got[0])
+	}
+}
+
+func TestDecoder_SequenceSplitAcrossFeeds(t *testing.T) {
+	var d Decoder
+	if got := d.Feed([]byte("\x1b[10")); len(got) != 0 {
+		t.Fatalf("expected incomplete sequence to wait, got %+v", got)
+	}
+	got := d.Feed([]byte("6;1:3u"))
+	if len(got) != 1 || got[0].Key != 'j' || got[0].Type != KeyRelease {
+		t.Fatalf("expected j release, got %+v", got)
+	}
+}
+
+func TestDecoder_IgnoresQueryReplyAndUnknownCSI(t *testing.T) {
+	var d Decoder
+	got := d.Feed([]byte("\x1b[?11u\x1b[2Jx"))
+	if len(got) != 1 || got[0].Key != 'x' {
+		t.Fatalf("expected only x, got %+v", got)
+	}
+}
+
+func TestDecoder_FlushReportsLoneEscape(t *testing.T) {
+	var d Decoder
+	if got := d.Feed([]byte{esc}); len(got) != 0 {
+		t.Fatalf("expected lone escape to wait, got %+v", got)
+	}
+	got := d.Flush()
+	if len(got) != 1 || got[0].Key != esc {
+		t.Fatalf("expected escape on flush, got %+v", got)
+	}
+	if got := d.Flush(); len(got) != 0 {
+		t.Fatalf("expected empty flush, got %+v", got)
+	}
+}
+
+func TestDecoder_FlushCutSequenceIsOneEscape(t *testing.T) {
+	for _, in := range []string{"\x1b[", "\x1b[1;5", "\x1bO"} {
+		var d Decoder
+		d.Feed([]byte(in))
+		if !d.Pending() {
+			t.Fatalf("%q: expected the sequence to wait", in)
+		}
+		got := d.Flush()
+		if len(got) != 1 || got[0].Key != KeyEscape {
+			t.Fatalf("%q: expected a single escape on flush, got %+v", in, got)
+		}
+	}
+}
+
+func TestStartEventReader_JoinsSequenceSplitAcrossReads(t *testing.T) {
+	r, w := io.Pipe()
+	ch := StartEventReader(r)
+	w.Write([]byte("\x1b["))
+	w.Write([]byte("D"))
+	if ev := <-ch; ev.Key != KeyArrowLeft {
+		t.Fatalf("expected arrow left, got %+v", ev)
+	}
+
+	// A lone Escape is reported once the line goes quiet.
+	w.Write([]byte{esc})
+	select {
+	case ev := <-ch:
+		if ev.Key != KeyEscape {
+			t.Fatalf("expected escape, got %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("lone escape was held back")
+	}
+	w.Close()
+	if ev, ok := <-ch; ok {
+		t.Fatalf("expected channel closed, got %+v", ev)
+	}
+}
+
+func TestStartEventReader_ClosesOnEOF(t *testing.T) {
+	ch := StartEventReader(strings.NewReader("q"))
+	ev, ok := <-ch
+	if !ok || ev.Key != 'q' {
+		t.Fatalf("expected q, got %+v ok=%v", ev, ok)
+	}
+	if _, ok := <-ch; ok {
+		t.Fatalf("expected channel closed after EOF")
+	}
+}
+
+func TestDecoder_NamedKeySequences(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Event
+	}{
+		{"\x1b[D", Event{Key: KeyArrowLeft}},
+		{"\x1bOC", Event{Key: KeyArrowRight}},
+		{"\x1b[1;5A", Event{Key: KeyArrowUp, Mods: ModCtrl}},
+		{"\x1b[1;1:3B", Event{Key: KeyArrowDown, Type: KeyRelease}},
+		{"\x1b[5~", Event{Key: KeyPageUp}},
+		{"\x1b[3;2~", Event{Key: KeyDelete, Mods: ModShift}},
+		{"\x1bOP", Event{Key: KeyF1}},
+		{"\x1b[15~", Event{Key: KeyF5}},
+		{"\x1b[24~", Event{Key: KeyF12}},
+		{"\x1b[H", Event{Key: KeyHome}},
+		{"\x1b[Z", Event{Key: KeyTab, Mods: ModShift}},
+		{"\x1b[57417u", Event{Key: KeyArrowLeft}},
+		{"\x1b[27u", Event{Key: KeyEscape}},
+		{"\x1b[32;1:3u", Event{Key: KeySpace, Type: KeyRelease}},
+		{"\r", Event{Key: KeyEnter}},
+		{"\t", Event{Key: KeyTab}},
+		{" ", Event{Key: KeySpace}},
+		{"\x7f", Event{Key: KeyBackspace}},
+		{"\x03", Event{Key: 'c', Mods: ModCtrl}},
+		{"\x1bx", Event{Key: 'x', Mods: ModAlt}},
+	}
+	for _, tt := range tests {
+		var d Decoder
+		got := d.Feed([]byte(tt.in))
+		if len(got) != 1 || got[0] != tt.want {
+			t.Fatalf("%q: got %+v want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDecoder_ArrowKeysDoNotLeakBytes(t *testing.T) {
+	var d Decoder
+	got := d.Feed([]byte("\x1b[A\x1b[B\x1b[C\x1b[D"))
+	want := []Key{KeyArrowUp, KeyArrowDown, KeyArrowRight, KeyArrowLeft}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i, k := range want {
+		if got[i].Key != k {
+			t.Fatalf("event %d: got %v want %v", i, got[i].Key, k)
+		}
+	}
+}
+
+func TestDecoder_DoubleEscape(t *testing.T) {
+	var d Decoder
+	got := d.Feed([]byte("\x1b\x1b[C"))
+	if len(got) != 2 || got[0].Key != KeyEscape || got[1].Key != KeyArrowRight {
+		t.Fatalf("expected Escape then ArrowRight, got %+v", got)
+	}
+}
@@ -2,29 +2,96 @@
 package input
 
 import (
-	"bufio"
+	"bytes"
+	"io"
 	"os"
+	"time"
 )
 
-type Key byte
+// Key is a Unicode code point as reported by the terminal.
+type Key rune
 
 const (
 	KeyUnknown Key = 0
 	KeyQuit    Key = 'q'
 )
 
-func StartKeyReader() <-chan Key {
-	ch := make(chan Key, 64)
+// Upper folds ASCII lowercase letters to uppercase so bindings match
+// regardless of shift or caps lock.
+func (k Key) Upper() Key {
+	if k >= 'a' && k <= 'z' {
+		return k - 32
+	}
+	return k
+}
+
+// Mod is a bit set of modifier keys held during an event.
+type Mod uint8
+
+const (
+	ModShift Mod = 1 << iota
+	ModAlt
+	ModCtrl
+	ModSuper
+)
+
+// StartKeyReader decodes key events from typed, bytes already read from
+// the terminal, and then from stdin until it is closed.
+func StartKeyReader(typed []byte) <-chan Event {
+	return StartEventReader(io.MultiReader(bytes.NewReader(typed), os.Stdin))
+}
+
+// escTimeout is how long an incomplete escape sequence waits for the rest
+// of its bytes before it counts as a bare Escape. Terminals write each
+// sequence in one go, but a slow link can still split it across reads.
+const escTimeout = 50 * time.Millisecond
+
+// StartEventReader decodes key events from r on a background goroutine.
+// The returned channel is closed when r returns an error.
+func StartEventReader(r io.Reader) <-chan Event {
+	ch := make(chan Event, 64)
+	chunks := make(chan []byte)
 
 	go func() {
-		r := bufio.NewReaderSize(os.Stdin, 64)
+		defer close(chunks)
 		for {
-			b, err := r.ReadByte()
+			buf := make([]byte, 4096)
+			n, err := r.Read(buf)
+			if n > 0 {
+				chunks <- buf[:n]
+			}
 			if err != nil {
-				close(ch)
 				return
 			}
-			ch <- Key(b)
+		}
+	}()
+
+	go func() {
+		defer close(ch)
+		var d Decoder
+		var idle <-chan time.Time
+		for {
+			select {
+			case p, ok := <-chunks:
+				if !ok {
+					for _, ev := range d.Flush() {
+						ch <- ev
+					}
+					return
+				}
+				for _, ev := range d.Feed(p) {
+					ch <- ev
+				}
+				idle = nil
+				if d.Pending() {
+					idle = time.After(escTimeout)
+				}
+			case <-idle:
+				for _, ev := range d.Flush() {
+					ch <- ev
+				}
+				idle = nil
+			}
 		}
 	}()
 
@@ -0,0 +1,96 @@
+package input
+
+import (
+	"sort"
+	"time"
+)
+
+// EventType tells whether a key went down, repeated or went up.
+type EventType uint8
+
+const (
+	KeyPress EventType = iota
+	KeyRelease
+	// KeyRepeat is an auto-repeat reported by terminals that distinguish it
+	// from a fresh press.
+	KeyRepeat
+)
+
+// Event is a single key transition.
+type Event struct {
+	Key  Key
+	Type EventType
+	Mods Mod
+}
+
+// Plain terminals only report key presses and, while a key is held, the
+// auto-repeat stream. These defaults cover the common X11/macOS settings.
+const (
+	// DefaultRepeatDelay is how long a key is assumed held after its first
+	// press; it must outlast the terminal's delay before auto-repeat starts.
+	DefaultRepeatDelay = 550 * time.Millisecond
+	// DefaultRepeatTimeout is the gap between repeats after which a
+	// repeating key is considered released.
+	DefaultRepeatTimeout = 100 * time.Millisecond
+)
+
+type heldKey struct {
+	last      time.Time
+	repeating bool
+}
+
+// KeyState turns the byte stream of a plain terminal into press and release
+// events by watching the auto-repeat stream: a key is pressed the first time
+// it is seen and released once its repeats stop arriving.
+type KeyState struct {
+	RepeatDelay   time.Duration
+	RepeatTimeout time.Duration
+
+	held map[Key]heldKey
+}
+
+func NewKeyState() *KeyState {
+	return &KeyState{
+		RepeatDelay:   DefaultRepeatDelay,
+		RepeatTimeout: DefaultRepeatTimeout,
+		held:          make(map[Key]heldKey),
+	}
+}
+
+// Observe records that k arrived from the terminal at now. It returns a
+// press event the first time k is seen and nothing for auto-repeats.
+func (s *KeyState) Observe(k Key, now time.Time) []Event {
+	h, ok := s.held[k]
+	if ok {
+		h.last = now
+		h.repeating = true
+		s.held[k] = h
+		return nil
+	}
+	s.held[k] = heldKey{last: now}
+	return []Event{{Key: k, Type: KeyPress}}
+}
+
+// Expire returns release events, in key order, for every held key whose
+// repeats have stopped arriving by now.
+func (s *KeyState) Expire(now time.Time) []Event {
+	var out []Event
+	for k, h := range s.held {
+		timeout := s.RepeatDelay
+		if h.repeating {
+			timeout = s.RepeatTimeout
+		}
+		if now.Sub(h.last) >= timeout {
+			delete(s.held, k)
+			out = append(out, Event{Key: k, Type: KeyRelease})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// Held reports whether k is currently considered down.
+func (s *KeyState) Held(k Key) bool {
+	_, ok := s.held[k]
+	return ok
+}
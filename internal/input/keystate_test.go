@@ -0,0 +1,90 @@
+package input
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyState_FirstObservationPresses(t *testing.T) {
+	s := NewKeyState()
+	now := time.Unix(0, 0)
+
+	got := s.Observe('A', now)
+	if len(got) != 1 || got[0] != (Event{Key: 'A', Type: KeyPress}) {
+		t.Fatalf("expected single press, got %+v", got)
+	}
+	if !s.Held('A') {
+		t.Fatalf("expected A held after press")
+	}
+
+	if got := s.Observe('A', now.Add(10*time.Millisecond)); len(got) != 0 {
+		t.Fatalf("expected auto-repeat to emit nothing, got %+v", got)
+	}
+}
+
+func TestKeyState_ReleaseAfterRepeatDelayWithoutRepeats(t *testing.T) {
+	s := NewKeyState()
+	now := time.Unix(0, 0)
+	s.Observe('A', now)
+
+	if got := s.Expire(now.Add(s.RepeatDelay - time.Millisecond)); len(got) != 0 {
+		t.Fatalf("expected key still held before repeat delay, got %+v", got)
+	}
+	got := s.Expire(now.Add(s.RepeatDelay))
+	if len(got) != 1 || got[0] != (Event{Key: 'A', Type: KeyRelease}) {
+		t.Fatalf("expected release after repeat delay, got %+v", got)
+	}
+	if s.Held('A') {
+		t.Fatalf("expected A not held after release")
+	}
+}
+
+func TestKeyState_RepeatingKeyUsesShortTimeout(t *testing.T) {
+	s := NewKeyState()
+	now := time.Unix(0, 0)
+	s.Observe('D', now)
+	now = now.Add(500 * time.Millisecond)
+	s.Observe('D', now)
+	now = now.Add(30 * time.Millisecond)
+	s.Observe('D', now)
+
+	if got := s.Expire(now.Add(s.RepeatTimeout / 2)); len(got) != 0 {
+		t.Fatalf("expected repeating key still held, got %+v", got)
+	}
+	got := s.Expire(now.Add(s.RepeatTimeout))
+	if len(got) != 1 || got[0].Type != KeyRelease || got[0].Key != 'D' {
+		t.Fatalf("expected release once repeats stop, got %+v", got)
+	}
+}
+
+func TestKeyState_PressAgainAfterRelease(t *testing.T) {
+	s := NewKeyState()
+	now := time.Unix(0, 0)
+	s.Observe('W', now)
+	now = now.Add(time.Second)
+	s.Expire(now)
+
+	got := s.Observe('W', now)
+	if len(got) != 1 || got[0].Type != KeyPress {
+		t.Fatalf("expected new press after release, got %+v", got)
+	}
+}
+
+func TestKeyState_ExpireOrdersByKey(t *testing.T) {
+	s := NewKeyState()
+	now := time.Unix(0, 0)
+	s.Observe('L', now)
+	s.Observe('A', now)
+	s.Observe('J', now)
+
+	got := s.Expire(now.Add(time.Second))
+	want := []Key{'A', 'J', 'L'}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d releases, got %+v", len(want), got)
+	}
+	for i, k := range want {
+		if got[i].Key != k {
+			t.Fatalf("release %d: got %q want %q", i, got[i].Key, k)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package input
+
+import "bytes"
+
+// Kitty progressive keyboard enhancement, see
+// https://sw.kovidgoyal.net/kitty/keyboard-protocol/.
+const (
+	// kittyQuery asks for the current keyboard flags, followed by a primary
+	// device attributes request every terminal answers. A DA reply without a
+	// flags reply before it means the protocol is not supported.
+	kittyQuery = "\x1b[?u\x1b[c"
+
+	// kittyPush enables flags 1|2|8: disambiguated escape codes,
+	// press/repeat/release event types and escape codes for every key.
+	kittyPush = "\x1b[>11u"
+	kittyPop  = "\x1b[<u"
+)
+
+// parseKittyReply scans the terminal's answer to kittyQuery. done is true
+// once the device attributes reply has arrived. typed is every byte that
+// is not part of a reply, such as keys pressed while waiting for it.
+func parseKittyReply(b []byte) (supported, done bool, typed []byte) {
+	for {
+		i := bytes.Index(b, []byte("\x1b[?"))
+		if i < 0 {
+			return supported, false, append(typed, b...)
+		}
+		typed = append(typed, b[:i]...)
+
+		j := i + 3
+		for j < len(b) && (b[j] >= '0' && b[j] <= '9' || b[j] == ';') {
+			j++
+		}
+		if j >= len(b) {
+			return supported, false, append(typed, b[i:]...)
+		}
+		switch b[j] {
+		case 'u':
+			supported = true
+			j++
+		case 'c':
+			return supported, true, append(typed, b[j+1:]...)
+		default:
+			typed = append(typed, b[i:j]...)
+		}
+		b = b[j:]
+	}
+}
@@ -0,0 +1,29 @@
+package input
+
+import "testing"
+
+func TestParseKittyReply(t *testing.T) {
+	tests := []struct {
+		in              string
+		supported, done bool
+		typed           string
+	}{
+		{"", false, false, ""},
+		{"\x1b[?0u", true, false, ""},
+		{"\x1b[?0u\x1b[?62;22c", true, true, ""},
+		{"\x1b[?62;22c", false, true, ""},
+		{"junk\x1b[?1;2c", false, true, "junk"},
+		{"w\x1b[?0uad\x1b[?62;22ci", true, true, "wadi"},
+		{"w\x1b[?0", false, false, "w\x1b[?0"},
+		{"\x1b[A\x1b[?1x\x1b[?1c", false, true, "\x1b[A\x1b[?1x"},
+	}
+	for _, tt := range tests {
+		supported, done, typed := parseKittyReply([]byte(tt.in))
+		if supported != tt.supported || done != tt.done {
+			t.Fatalf("%q: got supported=%v done=%v want %v %v", tt.in, supported, done, tt.supported, tt.done)
+		}
+		if string(typed) != tt.typed {
+			t.Fatalf("%q: got typed %q want %q", tt.in, typed, tt.typed)
+		}
+	}
+}
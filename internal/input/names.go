@@ -0,0 +1,120 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Keys without a printable character. Control keys use their ASCII code,
+// which is also what the kitty protocol reports for them; the rest live
+// just past the Unicode range so they never collide with a character.
+const (
+	KeyTab       Key = '\t'
+	KeyEnter     Key = '\r'
+	KeyEscape    Key = esc
+	KeySpace     Key = ' '
+	KeyBackspace Key = 0x7f
+)
+
+const (
+	KeyArrowUp Key = unicode.MaxRune + 1 + iota
+	KeyArrowDown
+	KeyArrowLeft
+	KeyArrowRight
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyInsert
+	KeyDelete
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+)
+
+var keyNames = map[Key]string{
+	KeyTab:        "Tab",
+	KeyEnter:      "Enter",
+	KeyEscape:     "Escape",
+	KeySpace:      "Space",
+	KeyBackspace:  "Backspace",
+	KeyArrowUp:    "ArrowUp",
+	KeyArrowDown:  "ArrowDown",
+	KeyArrowLeft:  "ArrowLeft",
+	KeyArrowRight: "ArrowRight",
+	KeyHome:       "Home",
+	KeyEnd:        "End",
+	KeyPageUp:     "PageUp",
+	KeyPageDown:   "PageDown",
+	KeyInsert:     "Insert",
+	KeyDelete:     "Delete",
+	KeyF1:         "F1",
+	KeyF2:         "F2",
+	KeyF3:         "F3",
+	KeyF4:         "F4",
+	KeyF5:         "F5",
+	KeyF6:         "F6",
+	KeyF7:         "F7",
+	KeyF8:         "F8",
+	KeyF9:         "F9",
+	KeyF10:        "F10",
+	KeyF11:        "F11",
+	KeyF12:        "F12",
+}
+
+// keyAliases are accepted by ParseKey in addition to keyNames.
+var keyAliases = map[string]Key{
+	"esc":    KeyEscape,
+	"return": KeyEnter,
+	"up":     KeyArrowUp,
+	"down":   KeyArrowDown,
+	"left":   KeyArrowLeft,
+	"right":  KeyArrowRight,
+	"pgup":   KeyPageUp,
+	"pgdn":   KeyPageDown,
+	"del":    KeyDelete,
+	"ins":    KeyInsert,
+}
+
+// String returns the name ParseKey accepts for k.
+func (k Key) String() string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	if k > 0 && k <= unicode.MaxRune && unicode.IsPrint(rune(k)) {
+		return string(rune(k))
+	}
+	return fmt.Sprintf("Key(%d)", int32(k))
+}
+
+// ParseKey resolves a key name such as "a", "ArrowLeft" or "Space"
+// (case-insensitive) to a Key. Letters are returned in uppercase to match
+// Key.Upper.
+func ParseKey(name string) (Key, error) {
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		if unicode.IsPrint(r) {
+			return Key(r).Upper(), nil
+		}
+	}
+	for k, n := range keyNames {
+		if strings.EqualFold(n, name) {
+			return k, nil
+		}
+	}
+	if k, ok := keyAliases[strings.ToLower(name)]; ok {
+		return k, nil
+	}
+	return KeyUnknown, fmt.Errorf("unknown key %q", name)
+}
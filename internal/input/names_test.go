@@ -0,0 +1,55 @@
+package input
+
+import "testing"
+
+func TestParseKey_NamesAndCharacters(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Key
+	}{
+		{"a", 'A'},
+		{"Q", 'Q'},
+		{"1", '1'},
+		{" ", KeySpace},
+		{"Space", KeySpace},
+		{"space", KeySpace},
+		{"ArrowLeft", KeyArrowLeft},
+		{"ARROWRIGHT", KeyArrowRight},
+		{"Left", KeyArrowLeft},
+		{"Enter", KeyEnter},
+		{"Esc", KeyEscape},
+		{"Tab", KeyTab},
+		{"F1", KeyF1},
+		{"f12", KeyF12},
+		{"PageDown", KeyPageDown},
+	}
+	for _, tt := range tests {
+		got, err := ParseKey(tt.in)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Fatalf("%q: got %v want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseKey_Unknown(t *testing.T) {
+	for _, in := range []string{"", "AB", "F13", "Arrow", "\x01"} {
+		if _, err := ParseKey(in); err == nil {
+			t.Fatalf("%q: expected error", in)
+		}
+	}
+}
+
+func TestKeyString_RoundTrips(t *testing.T) {
+	for k := range keyNames {
+		got, err := ParseKey(k.String())
+		if err != nil || got != k {
+			t.Fatalf("%v: round trip got %v err %v", k, got, err)
+		}
+	}
+	if got := Key('A').String(); got != "A" {
+		t.Fatalf("got %q want %q", got, "A")
+	}
+}
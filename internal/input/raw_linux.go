@@ -1,7 +1,9 @@
 package input
 
 import (
+	"errors"
 	"os"
+	"time"
 
 	"golang.org/x/sys/unix"
 )
@@ -9,6 +11,9 @@ import (
 type RawTerminal struct {
 	fd    int
 	state *unix.Termios
+
+	out   *os.File
+	kitty bool
 }
 
 func MakeRaw() (*RawTerminal, error) {
@@ -35,9 +40,74 @@ func MakeRaw() (*RawTerminal, error) {
 		return nil, err
 	}
 
-	return &RawTerminal{fd: fd, state: oldState}, nil
+	return &RawTerminal{fd: fd, state: oldState, out: os.Stdout}, nil
+}
+
+// EnableKittyKeyboard asks the terminal whether it supports the kitty
+// keyboard protocol and, if it answers within timeout, switches it on so
+// key releases are reported. It returns false when the terminal stays
+// silent; the plain byte stream is then left untouched. typed holds the
+// bytes read while waiting that are not part of the answer, such as keys
+// pressed meanwhile, for StartKeyReader.
+func (t *RawTerminal) EnableKittyKeyboard(timeout time.Duration) (supported bool, typed []byte, err error) {
+	if _, err := t.out.WriteString(kittyQuery); err != nil {
+		return false, nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	var reply []byte
+	buf := make([]byte, 256)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false, typed, nil
+		}
+		fds := []unix.PollFd{{Fd: int32(t.fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, int(remaining/time.Millisecond)+1)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return false, typed, err
+		}
+		if n == 0 {
+			return false, typed, nil
+		}
+
+		m, err := unix.Read(t.fd, buf)
+		if err != nil {
+			return false, typed, err
+		}
+		reply = append(reply, buf[:m]...)
+
+		var done bool
+		supported, done, typed = parseKittyReply(reply)
+		if !done {
+			continue
+		}
+		if supported {
+			if _, err := t.out.WriteString(kittyPush); err != nil {
+				return false, typed, err
+			}
+			t.kitty = true
+		}
+		return supported, typed, nil
+	}
+}
+
+// Size returns the terminal's current size in cells.
+func (t *RawTerminal) Size() (width, height int, err error) {
+	ws, err := unix.IoctlGetWinsize(t.fd, unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Col), int(ws.Row), nil
 }
 
 func (t *RawTerminal) Restore() error {
+	if t.kitty {
+		_, _ = t.out.WriteString(kittyPop)
+		t.kitty = false
+	}
 	return unix.IoctlSetTermios(t.fd, unix.TCSETS, t.state)
 }
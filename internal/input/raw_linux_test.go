@@ -38,3 +38,10 @@ func TestRawTerminal_Restore_InvalidFD(t *testing.T) {
 		t.Fatalf("expected error restoring invalid fd, got nil")
 	}
 }
+
+func TestRawTerminal_Size_InvalidFD(t *testing.T) {
+	rt := &RawTerminal{fd: -1}
+	if _, _, err := rt.Size(); err == nil {
+		t.Fatalf("expected error querying size of invalid fd, got nil")
+	}
+}
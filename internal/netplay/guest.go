@@ -0,0 +1,178 @@
+package netplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"terminalvolley/internal/engine"
+)
+
+// Guest is a connection to a host's game.
+type Guest struct {
+	conn    net.Conn
+	welcome Welcome
+	snaps   chan engine.Snapshot
+	done    chan struct{} // closed when the host stopped talking
+
+	sendMu sync.Mutex
+	sent   keys
+
+	mu     sync.Mutex
+	closed bool
+	err    error
+}
+
+// JoinConn connects to the host at addr and returns the connection and
+// the host's welcome, waiting up to timeout for it. An address without a
+// port uses DefaultPort.
+func JoinConn(addr string, timeout time.Duration) (net.Conn, Welcome, error) {
+	conn, err := net.DialTimeout("tcp", withPort(addr), timeout)
+	if err != nil {
+		return nil, Welcome{}, err
+	}
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	r := bufio.NewReader(conn)
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		conn.Close()
+		return nil, Welcome{}, fmt.Errorf("join %s: %w", addr, err)
+	}
+	var w Welcome
+	if err := json.Unmarshal(line, &w); err != nil {
+		conn.Close()
+		return nil, Welcome{}, fmt.Errorf("join %s: bad welcome: %w", addr, err)
+	}
+	if w.Version != Version {
+		conn.Close()
+		return nil, Welcome{}, fmt.Errorf("join %s: host speaks protocol version %d, want %d", addr, w.Version, Version)
+	}
+	if err := w.check(); err != nil {
+		conn.Close()
+		return nil, Welcome{}, fmt.Errorf("join %s: %w", addr, err)
+	}
+	conn.SetReadDeadline(time.Time{})
+	return bufferedConn{conn, r}, w, nil
+}
+
+// bufferedConn is a connection whose first bytes have been read ahead.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c bufferedConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+// NewGuest plays the match the host welcomed conn to with w as snapshots.
+func NewGuest(conn net.Conn, w Welcome) *Guest {
+	g := &Guest{
+		conn:    conn,
+		welcome: w,
+		snaps:   make(chan engine.Snapshot, 64),
+		done:    make(chan struct{}),
+	}
+	go g.read(conn)
+	return g
+}
+
+// Welcome returns what the host told about the match.
+func (g *Guest) Welcome() Welcome { return g.welcome }
+
+// Send tells the host the keys the guest holds. Unchanged keys are not
+// sent again; a serve request always is.
+func (g *Guest) Send(in engine.Input) error {
+	k := keys{Left: in.Left, Right: in.Right, Jump: in.Jump, Serve: in.Serve}
+	g.sendMu.Lock()
+	defer g.sendMu.Unlock()
+	if k == g.sent && !k.Serve {
+		return nil
+	}
+	line, err := json.Marshal(k)
+	if err != nil {
+		return err
+	}
+	if _, err := g.conn.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	g.sent = k
+	g.sent.Serve = false
+	return nil
+}
+
+// Snapshots delivers the host's snapshots in order. If the guest does not
+// keep up, the oldest are dropped.
+func (g *Guest) Snapshots() <-chan engine.Snapshot { return g.snaps }
+
+// Done is closed once the host has gone.
+func (g *Guest) Done() <-chan struct{} { return g.done }
+
+// Err returns why the host went, or nil while the match goes on.
+func (g *Guest) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+func (g *Guest) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.err == nil && !g.closed {
+		g.err = fmt.Errorf("host %s: %w", g.conn.RemoteAddr(), err)
+	}
+}
+
+// Close leaves the game.
+func (g *Guest) Close() error {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return nil
+	}
+	g.closed = true
+	g.mu.Unlock()
+
+	err := g.conn.Close()
+	<-g.done
+	return err
+}
+
+// queue hands s to Snapshots, dropping the oldest snapshot if the buffer
+// is full.
+func (g *Guest) queue(s engine.Snapshot) {
+	for {
+		select {
+		case g.snaps <- s:
+			return
+		default:
+		}
+		select {
+		case <-g.snaps:
+		default:
+		}
+	}
+}
+
+// read takes the host's snapshots until it hangs up or sends garbage.
+func (g *Guest) read(r io.Reader) {
+	defer close(g.done)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		var s engine.Snapshot
+		if err := json.Unmarshal(sc.Bytes(), &s); err != nil {
+			g.fail(fmt.Errorf("bad snapshot: %w", err))
+			g.conn.Close()
+			return
+		}
+		g.queue(s)
+	}
+	err := sc.Err()
+	if err == nil {
+		err = errors.New("ended the game")
+	}
+	g.fail(err)
+}
@@ -0,0 +1,179 @@
+package netplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"terminalvolley/internal/engine"
+)
+
+// Listen opens a TCP listener for guests on addr; an address without a
+// port uses DefaultPort.
+func Listen(addr string) (net.Listener, error) {
+	return net.Listen("tcp", withPort(addr))
+}
+
+// Host is the host's end of the connection to the guest. It implements
+// engine.Controller for the guest's player: Control returns the keys the
+// guest holds. A guest that leaves stands still.
+type Host struct {
+	conn net.Conn
+	done chan struct{} // closed when the guest stopped talking
+
+	// mu guards the guest's keys and the outgoing snapshot. Only the
+	// newest snapshot is kept, so a slow link skips frames instead of
+	// falling ever further behind.
+	mu      sync.Mutex
+	wake    *sync.Cond
+	keys    keys
+	serve   bool
+	pending []byte
+	closed  bool
+	err     error
+}
+
+// NewHost streams snapshots to the guest welcomed on conn.
+func NewHost(conn net.Conn) *Host {
+	h := &Host{conn: conn, done: make(chan struct{})}
+	h.wake = sync.NewCond(&h.mu)
+	go h.read()
+	go h.write()
+	return h
+}
+
+// AcceptConn waits for a guest on l, welcomes it and returns the
+// connection for the netcode to use.
+func AcceptConn(l net.Listener, w Welcome) (net.Conn, error) {
+	conn, err := l.Accept()
+	if err != nil {
+		return nil, err
+	}
+	line, err := json.Marshal(w)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := conn.Write(append(line, '\n')); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("welcome %s: %w", conn.RemoteAddr(), err)
+	}
+	return conn, nil
+}
+
+// Control implements engine.Controller.
+func (h *Host) Control(g *engine.Game, i int) engine.Input {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return engine.Input{}
+	}
+	in := engine.Input{Left: h.keys.Left, Right: h.keys.Right, Jump: h.keys.Jump, Serve: h.serve}
+	h.serve = false
+	return in
+}
+
+// Send queues the state of g for the guest, replacing a snapshot that has
+// not gone out yet.
+func (h *Host) Send(g *engine.Game) {
+	line, err := json.Marshal(g.Snapshot())
+	if err != nil {
+		h.fail(err)
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pending = line
+	h.wake.Signal()
+}
+
+// Done is closed once the guest has left.
+func (h *Host) Done() <-chan struct{} { return h.done }
+
+// Err returns why the guest left, or nil while it plays.
+func (h *Host) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+func (h *Host) fail(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err == nil {
+		h.err = fmt.Errorf("guest %s: %w", h.conn.RemoteAddr(), err)
+	}
+}
+
+// Close hangs up on the guest.
+func (h *Host) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	h.wake.Signal()
+	h.mu.Unlock()
+
+	err := h.conn.Close()
+	<-h.done
+	return err
+}
+
+// write sends queued snapshots until the host is closed.
+func (h *Host) write() {
+	w := bufio.NewWriter(h.conn)
+	for {
+		h.mu.Lock()
+		for h.pending == nil && !h.closed {
+			h.wake.Wait()
+		}
+		if h.closed {
+			h.mu.Unlock()
+			return
+		}
+		line := h.pending
+		h.pending = nil
+		h.mu.Unlock()
+
+		w.Write(line)
+		w.WriteByte('\n')
+		// A failed write means the guest is gone; read reports why.
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// read takes the guest's keys until it hangs up or sends garbage.
+func (h *Host) read() {
+	defer close(h.done)
+	sc := bufio.NewScanner(h.conn)
+	for sc.Scan() {
+		var k keys
+		if err := json.Unmarshal(sc.Bytes(), &k); err != nil {
+			h.fail(fmt.Errorf("bad input %q: %w", sc.Text(), err))
+			h.conn.Close()
+			return
+		}
+		h.mu.Lock()
+		h.keys = k
+		h.serve = h.serve || k.Serve
+		h.mu.Unlock()
+	}
+	h.mu.Lock()
+	closed := h.closed
+	h.mu.Unlock()
+	if closed {
+		return
+	}
+	err := sc.Err()
+	if err == nil {
+		err = errors.New("left the game")
+	}
+	h.fail(err)
+}
@@ -0,0 +1,254 @@
+package netplay
+
+import (
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"terminalvolley/internal/engine"
+)
+
+// connect starts a host for g on localhost and joins it.
+func connect(t *testing.T, g *engine.Game, player int) (*Host, *Guest) {
+	t.Helper()
+	l, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	hosts := make(chan *Host, 1)
+	go func() {
+		conn, err := AcceptConn(l, NewWelcome(g, player))
+		if err != nil {
+			t.Error(err)
+			hosts <- nil
+			return
+		}
+		hosts <- NewHost(conn)
+	}()
+	conn, w, err := JoinConn(l.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	guest := NewGuest(conn, w)
+	host := <-hosts
+	if host == nil {
+		t.FailNow()
+	}
+	t.Cleanup(func() {
+		guest.Close()
+		host.Close()
+	})
+	return host, guest
+}
+
+func TestGuestMirrorsHost(t *testing.T) {
+	g := engine.New(80, 24, engine.WithTickRate(120), engine.WithTeamSize(2))
+	host, guest := connect(t, g, engine.Player2)
+
+	w := guest.Welcome()
+	if w.Player != engine.Player2 || w.TickRate != 120 || w.TeamSize != 2 {
+		t.Fatalf("welcome = %+v", w)
+	}
+	replica := w.NewGame()
+
+	// The guest serves and walks left; the host runs the match.
+	g.Press(engine.Player1, engine.ActionServe)
+	if err := guest.Send(engine.Input{Left: true}); err != nil {
+		t.Fatal(err)
+	}
+	startX := g.Player(engine.Player2).X
+	deadline := time.After(2 * time.Second)
+	for {
+		in := host.Control(g, engine.Player2)
+		g.SetInput(engine.Player2, in)
+		g.Step()
+		host.Send(g)
+
+		select {
+		case s := <-guest.Snapshots():
+			if err := replica.Restore(s); err != nil {
+				t.Fatal(err)
+			}
+		case <-deadline:
+			t.Fatalf("the guest's player never moved: host at x=%.1f, replica at x=%.1f", g.Player(engine.Player2).X, replica.Player(engine.Player2).X)
+		case <-time.After(time.Millisecond):
+		}
+		if replica.Player(engine.Player2).X < startX-5 {
+			break
+		}
+	}
+
+	// Once the stream settles the replica is the host's game.
+	time.Sleep(20 * time.Millisecond)
+	for len(guest.Snapshots()) > 0 {
+		<-guest.Snapshots()
+	}
+	host.Send(g)
+	select {
+	case s := <-guest.Snapshots():
+		replica.Restore(s)
+	case <-time.After(time.Second):
+		t.Fatal("no snapshot")
+	}
+	if !reflect.DeepEqual(replica.Snapshot(), g.Snapshot()) {
+		t.Fatalf("replica differs from the host:\n got %+v\nwant %+v", replica.Snapshot(), g.Snapshot())
+	}
+}
+
+func TestHostPassesServeOnce(t *testing.T) {
+	g := engine.New(80, 24)
+	host, guest := connect(t, g, engine.Player2)
+	if err := guest.Send(engine.Input{Jump: true, Serve: true}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	var in engine.Input
+	for !in.Serve && time.Now().Before(deadline) {
+		in = host.Control(g, engine.Player2)
+		time.Sleep(time.Millisecond)
+	}
+	if !in.Serve || !in.Jump {
+		t.Fatalf("first input = %+v, want jump and serve", in)
+	}
+	if in := host.Control(g, engine.Player2); in.Serve || !in.Jump {
+		t.Fatalf("second input = %+v, want jump held and no serve", in)
+	}
+}
+
+func TestHostNoticesGuestLeaving(t *testing.T) {
+	g := engine.New(80, 24)
+	host, guest := connect(t, g, engine.Player2)
+	guest.Send(engine.Input{Right: true})
+	guest.Close()
+	select {
+	case <-host.Done():
+	case <-time.After(time.Second):
+		t.Fatal("host did not notice the guest leaving")
+	}
+	if host.Err() == nil {
+		t.Fatal("expected an error after the guest left")
+	}
+	if in := host.Control(g, engine.Player2); in != (engine.Input{}) {
+		t.Fatalf("a gone guest should stand still, got %+v", in)
+	}
+}
+
+func TestGuestNoticesHostLeaving(t *testing.T) {
+	g := engine.New(80, 24)
+	host, guest := connect(t, g, engine.Player2)
+	host.Close()
+	select {
+	case <-guest.Done():
+	case <-time.After(time.Second):
+		t.Fatal("guest did not notice the host leaving")
+	}
+	if guest.Err() == nil {
+		t.Fatal("expected an error after the host left")
+	}
+}
+
+func TestJoinRejectsOtherVersion(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte(`{"version":99}` + "\n"))
+	}()
+	if _, _, err := JoinConn(l.Addr().String(), time.Second); err == nil {
+		t.Fatal("expected joining a host of another version to fail")
+	}
+}
+
+func TestJoinConnRejectsBadWelcome(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	good := NewWelcome(engine.New(80, 24), engine.Player2)
+	bad := []func(*Welcome){
+		func(w *Welcome) { w.Player = 2 },
+		func(w *Welcome) { w.Player = -1 },
+		func(w *Welcome) { w.TeamSize = 0 },
+		func(w *Welcome) { w.TeamSize = engine.MaxTeamSize + 1 },
+		func(w *Welcome) { w.Width = 0 },
+		func(w *Welcome) { w.TickRate = 5 },
+		func(w *Welcome) { w.TickRate = engine.MaxTickRate + 1 },
+		func(w *Welcome) { w.Physics.Gravity = -1 },
+	}
+	go func() {
+		for _, b := range bad {
+			w := good
+			b(&w)
+			conn, err := AcceptConn(l, w)
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	for i := range bad {
+		if conn, w, err := JoinConn(l.Addr().String(), time.Second); err == nil {
+			conn.Close()
+			t.Errorf("case %d: joined with welcome %+v", i, w)
+		}
+	}
+}
+
+func TestWithPort(t *testing.T) {
+	for addr, want := range map[string]string{
+		"":               ":" + DefaultPort,
+		"example.org":    "example.org:" + DefaultPort,
+		"10.0.0.2:7000":  "10.0.0.2:7000",
+		"::1":            "[::1]:" + DefaultPort,
+		"[::1]:7000":     "[::1]:7000",
+		"localhost:http": "localhost:http",
+	} {
+		if got := withPort(addr); got != want {
+			t.Errorf("withPort(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestJoinConnHandsOverRollback(t *testing.T) {
+	l, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	g := engine.New(80, 24)
+	w := NewWelcome(g, engine.Player2)
+	w.Netcode = NetcodeRollback
+	go func() {
+		conn, err := AcceptConn(l, w)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// The first packet follows the welcome at once.
+		conn.Write([]byte("packet\n"))
+	}()
+
+	conn, got, err := JoinConn(l.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if got.Netcode != NetcodeRollback {
+		t.Fatalf("netcode = %q, want %q", got.Netcode, NetcodeRollback)
+	}
+	buf := make([]byte, 7)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "packet\n" {
+		t.Fatalf("read %q, %v after the welcome, want the first packet", buf, err)
+	}
+}
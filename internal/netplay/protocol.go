@@ -0,0 +1,108 @@
+// Package netplay lets two people play one match over TCP. The host's
+// first line is a Welcome describing the match and the netcode. With
+// snapshots the host runs the only real Game: the guest sends its keys
+// upstream whenever they change, and the host streams complete snapshots
+// of the match downstream for the guest to draw. Both directions are JSON
+// lines. With rollback both run the match and the connection carries
+// rollback packets after the welcome.
+package netplay
+
+import (
+	"fmt"
+	"math"
+	"net"
+
+	"terminalvolley/internal/engine"
+)
+
+// Version is the protocol version. A guest only joins a host speaking the
+// same one.
+const Version = 2
+
+// DefaultPort is the TCP port used when an address names none.
+const DefaultPort = "5757"
+
+// The netcodes a host can offer.
+const (
+	NetcodeSnapshots = "snapshots"
+	NetcodeRollback  = "rollback"
+)
+
+// Welcome tells a guest which player it is and how to build a replica of
+// the host's game.
+type Welcome struct {
+	Version  int            `json:"version"`
+	Netcode  string         `json:"netcode"`
+	Player   int            `json:"player"`
+	Width    int            `json:"width"`
+	Height   int            `json:"height"`
+	TickRate int            `json:"tickRate"`
+	TeamSize int            `json:"teamSize"`
+	Physics  engine.Physics `json:"physics"`
+	Rules    engine.Rules   `json:"rules"`
+}
+
+// NewWelcome describes g to the guest playing player, with snapshots.
+func NewWelcome(g *engine.Game, player int) Welcome {
+	return Welcome{
+		Version:  Version,
+		Netcode:  NetcodeSnapshots,
+		Player:   player,
+		Width:    g.W,
+		Height:   g.H,
+		TickRate: int(math.Round(1 / g.DT())),
+		TeamSize: g.TeamSize(),
+		Physics:  g.Physics(),
+		Rules:    g.Rules(),
+	}
+}
+
+// NewGame returns a game matching the host's, which host snapshots can be
+// restored into.
+func (w Welcome) NewGame() *engine.Game {
+	return engine.New(w.Width, w.Height,
+		engine.WithTickRate(w.TickRate),
+		engine.WithTeamSize(w.TeamSize),
+		engine.WithPhysics(w.Physics),
+		engine.WithRules(w.Rules),
+	)
+}
+
+// check rejects a welcome whose player or match cannot be built, so a
+// faulty host cannot crash the guest.
+func (w Welcome) check() error {
+	if w.TeamSize < 1 || w.TeamSize > engine.MaxTeamSize {
+		return fmt.Errorf("bad team size %d", w.TeamSize)
+	}
+	if w.Player < 0 || w.Player >= 2*w.TeamSize {
+		return fmt.Errorf("bad player %d for %d players", w.Player, 2*w.TeamSize)
+	}
+	if w.Width <= 0 || w.Height <= 0 {
+		return fmt.Errorf("bad arena %dx%d", w.Width, w.Height)
+	}
+	// The guest would clamp the rate and run at a different one.
+	if w.TickRate < engine.MinTickRate || w.TickRate > engine.MaxTickRate {
+		return fmt.Errorf("bad tick rate %d", w.TickRate)
+	}
+	if err := w.Physics.Validate(); err != nil {
+		return fmt.Errorf("bad physics: %w", err)
+	}
+	return nil
+}
+
+// keys is the line a guest sends when its input changes. Serve is a
+// one-shot request like in engine.Input.
+type keys struct {
+	Left  bool `json:"left"`
+	Right bool `json:"right"`
+	Jump  bool `json:"jump"`
+	Serve bool `json:"serve"`
+}
+
+// withPort adds DefaultPort to an address without a port.
+func withPort(addr string) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+	return net.JoinHostPort(addr, DefaultPort)
+}
@@ -0,0 +1,135 @@
+// Package netsim simulates a network between two endpoints for tests:
+// packets are delayed by a latency plus random jitter, which can reorder
+// them, and some are lost. Time only moves when Advance is called, so a
+// test runs as fast as the machine allows and replays exactly from its
+// seed.
+package netsim
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Conditions describe the network one way; both directions share them.
+type Conditions struct {
+	// Latency is how long a packet takes to arrive and Jitter how much
+	// that varies either way, uniformly.
+	Latency time.Duration
+	Jitter  time.Duration
+	// Loss is the chance that a packet never arrives, from 0 to 1.
+	Loss float64
+}
+
+// Network connects two Endpoints.
+type Network struct {
+	mu   sync.Mutex
+	cond Conditions
+	rng  *rand.Rand
+	now  time.Duration
+	seq  int
+	ends [2]*Endpoint
+
+	sent, lost int
+}
+
+// Endpoint is one end of a Network. It implements rollback.Link.
+type Endpoint struct {
+	net     *Network
+	other   int
+	flights []flight // sorted by arrival
+}
+
+type flight struct {
+	at     time.Duration
+	seq    int
+	packet []byte
+}
+
+// New returns a network with the given conditions whose randomness comes
+// from seed.
+func New(c Conditions, seed int64) *Network {
+	n := &Network{cond: c, rng: rand.New(rand.NewSource(seed))}
+	n.ends[0] = &Endpoint{net: n, other: 1}
+	n.ends[1] = &Endpoint{net: n, other: 0}
+	return n
+}
+
+// Ends returns the two endpoints.
+func (n *Network) Ends() (*Endpoint, *Endpoint) { return n.ends[0], n.ends[1] }
+
+// SetConditions changes the conditions for packets sent from now on.
+func (n *Network) SetConditions(c Conditions) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cond = c
+}
+
+// Advance moves the network's clock forward by d.
+func (n *Network) Advance(d time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.now += d
+}
+
+// Now returns how much time has passed on the network's clock.
+func (n *Network) Now() time.Duration {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.now
+}
+
+// Sent counts the packets sent in both directions.
+func (n *Network) Sent() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.sent
+}
+
+// Lost counts the packets that never arrived.
+func (n *Network) Lost() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.lost
+}
+
+// Send puts a copy of packet on its way to the other endpoint.
+func (e *Endpoint) Send(packet []byte) error {
+	n := e.net
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent++
+	if n.rng.Float64() < n.cond.Loss {
+		n.lost++
+		return nil
+	}
+	delay := n.cond.Latency
+	if n.cond.Jitter > 0 {
+		delay += time.Duration((2*n.rng.Float64() - 1) * float64(n.cond.Jitter))
+	}
+	n.seq++
+	f := flight{at: n.now + max(delay, 0), seq: n.seq, packet: append([]byte(nil), packet...)}
+	to := n.ends[e.other]
+	i := sort.Search(len(to.flights), func(i int) bool {
+		g := to.flights[i]
+		return g.at > f.at || (g.at == f.at && g.seq > f.seq)
+	})
+	to.flights = append(to.flights, flight{})
+	copy(to.flights[i+1:], to.flights[i:])
+	to.flights[i] = f
+	return nil
+}
+
+// Receive returns the next packet that has arrived by now, if any.
+func (e *Endpoint) Receive() ([]byte, bool) {
+	n := e.net
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(e.flights) == 0 || e.flights[0].at > n.now {
+		return nil, false
+	}
+	p := e.flights[0].packet
+	e.flights = e.flights[1:]
+	return p, true
+}
@@ -0,0 +1,83 @@
+package netsim
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLatencyDelaysDelivery(t *testing.T) {
+	n := New(Conditions{Latency: 30 * time.Millisecond}, 1)
+	a, b := n.Ends()
+	a.Send([]byte("ping"))
+	n.Advance(29 * time.Millisecond)
+	if _, ok := b.Receive(); ok {
+		t.Fatal("packet arrived before the latency passed")
+	}
+	n.Advance(time.Millisecond)
+	if p, ok := b.Receive(); !ok || string(p) != "ping" {
+		t.Fatalf("got %q, %v; want ping", p, ok)
+	}
+	if _, ok := a.Receive(); ok {
+		t.Fatal("packet came back to the sender")
+	}
+}
+
+func TestJitterReordersWithinBounds(t *testing.T) {
+	c := Conditions{Latency: 50 * time.Millisecond, Jitter: 20 * time.Millisecond}
+	n := New(c, 1)
+	a, b := n.Ends()
+	const count = 200
+	// Nothing arrives before the shortest delay; everything by the longest.
+	got, reordered, last := 0, 0, -1
+	for tick := 0; n.Now() <= count*time.Millisecond+c.Latency+c.Jitter; tick++ {
+		if tick < count {
+			a.Send([]byte(fmt.Sprint(tick)))
+		}
+		for {
+			p, ok := b.Receive()
+			if !ok {
+				break
+			}
+			var i int
+			fmt.Sscan(string(p), &i)
+			if d := n.Now() - time.Duration(i)*time.Millisecond; d < c.Latency-c.Jitter || d > c.Latency+c.Jitter+time.Millisecond {
+				t.Fatalf("packet %d took %v", i, d)
+			}
+			if i < last {
+				reordered++
+			}
+			last = i
+			got++
+		}
+		n.Advance(time.Millisecond)
+	}
+	if got != count {
+		t.Fatalf("got %d packets, want %d", got, count)
+	}
+	if reordered == 0 {
+		t.Fatal("expected jitter to reorder some packets")
+	}
+}
+
+func TestLossDropsAShare(t *testing.T) {
+	n := New(Conditions{Loss: 0.25}, 1)
+	a, b := n.Ends()
+	const count = 4000
+	for i := 0; i < count; i++ {
+		b.Send([]byte{byte(i)})
+	}
+	got := 0
+	for {
+		if _, ok := a.Receive(); !ok {
+			break
+		}
+		got++
+	}
+	if got+n.Lost() != count || n.Sent() != count {
+		t.Fatalf("got %d and lost %d of %d sent, want %d in all", got, n.Lost(), n.Sent(), count)
+	}
+	if share := float64(n.Lost()) / count; share < 0.22 || share > 0.28 {
+		t.Fatalf("lost %.1f%%, want about 25%%", 100*share)
+	}
+}
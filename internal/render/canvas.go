@@ -0,0 +1,98 @@
+package render
+
+import "math"
+
+const upperHalfBlock = '▀'
+
+// Canvas is a pixel grid with twice the vertical resolution of a frame:
+// each cell shows a top and a bottom pixel using the upper half block (▀)
+// with the top pixel as foreground and the bottom one as background.
+//
+// Shapes are placed in cell coordinates, where cell (x, y) spans
+// [x-0.5, x+0.5) horizontally and [y-0.5, y+0.5) vertically, the same
+// rounding ASCII sprites use.
+type Canvas struct {
+	Cols, Rows int // size in cells
+	Pix        []Color
+}
+
+func NewCanvas(cols, rows int) *Canvas {
+	return &Canvas{
+		Cols: cols,
+		Rows: rows,
+		Pix:  make([]Color, cols*rows*2),
+	}
+}
+
+// Fill sets every pixel to c.
+func (c *Canvas) Fill(col Color) {
+	for i := range c.Pix {
+		c.Pix[i] = col
+	}
+}
+
+// SetPixel colors the pixel at column px, pixel row py (two per cell row).
+func (c *Canvas) SetPixel(px, py int, col Color) {
+	if px < 0 || py < 0 || px >= c.Cols || py >= c.Rows*2 {
+		return
+	}
+	c.Pix[py*c.Cols+px] = col
+}
+
+// Pixel returns the color at (px, py), or ColorDefault outside the canvas.
+func (c *Canvas) Pixel(px, py int) Color {
+	if px < 0 || py < 0 || px >= c.Cols || py >= c.Rows*2 {
+		return ColorDefault
+	}
+	return c.Pix[py*c.Cols+px]
+}
+
+// pixelCenter returns the cell-coordinate center of pixel (px, py).
+func pixelCenter(px, py int) (x, y float64) {
+	return float64(px), float64(py)/2 - 0.25
+}
+
+// FillDisc colors every pixel whose center lies within r of (x, y) and at
+// or above maxY (use +Inf for a full disc).
+func (c *Canvas) FillDisc(x, y, r, maxY float64, col Color) {
+	x0 := int(math.Floor(x - r))
+	x1 := int(math.Ceil(x + r))
+	y0 := int(math.Floor((y-r+0.25)*2)) - 1
+	y1 := int(math.Ceil((y+r+0.25)*2)) + 1
+	for py := y0; py <= y1; py++ {
+		for px := x0; px <= x1; px++ {
+			cx, cy := pixelCenter(px, py)
+			if cy > maxY {
+				continue
+			}
+			dx, dy := cx-x, cy-y
+			if dx*dx+dy*dy <= r*r {
+				c.SetPixel(px, py, col)
+			}
+		}
+	}
+}
+
+// FillColumn colors column x from cell row top to cell row bottom
+// (inclusive), both pixels of each row.
+func (c *Canvas) FillColumn(x, top, bottom int, col Color) {
+	for py := top * 2; py <= bottom*2+1; py++ {
+		c.SetPixel(x, py, col)
+	}
+}
+
+// DrawCanvas paints c into f with its top-left cell at (x, y). Cells whose
+// two pixels match are drawn as a blank with that background.
+func (f *Frame) DrawCanvas(c *Canvas, x, y int) {
+	for row := 0; row < c.Rows; row++ {
+		for col := 0; col < c.Cols; col++ {
+			top := c.Pix[(row*2)*c.Cols+col]
+			bottom := c.Pix[(row*2+1)*c.Cols+col]
+			cell := Cell{Ch: upperHalfBlock, Style: Style{Fg: top, Bg: bottom}}
+			if top == bottom {
+				cell = Cell{Ch: ' ', Style: Style{Bg: top}}
+			}
+			f.SetCell(x+col, y+row, cell)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package render
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCanvas_SetPixelClips(t *testing.T) {
+	c := NewCanvas(2, 1)
+	red := RGB(255, 0, 0)
+	c.SetPixel(-1, 0, red)
+	c.SetPixel(0, 2, red)
+	c.SetPixel(1, 1, red)
+	for i, p := range c.Pix {
+		want := ColorDefault
+		if i == 3 {
+			want = red
+		}
+		if p != want {
+			t.Fatalf("pixel %d: got %v want %v", i, p, want)
+		}
+	}
+}
+
+func TestCanvas_FillDiscDoublesVerticalResolution(t *testing.T) {
+	c := NewCanvas(5, 5)
+	red := RGB(255, 0, 0)
+	// A disc just under half a cell tall centered on the top half of cell
+	// (2, 2) only covers that one pixel vertically.
+	c.FillDisc(2, 1.75, 0.2, math.Inf(1), red)
+
+	if got := c.Pixel(2, 4); got != red {
+		t.Fatalf("expected top pixel of cell (2,2) set, got %v", got)
+	}
+	if got := c.Pixel(2, 5); got != ColorDefault {
+		t.Fatalf("expected bottom pixel of cell (2,2) clear, got %v", got)
+	}
+}
+
+func TestCanvas_FillDiscRespectsMaxY(t *testing.T) {
+	c := NewCanvas(5, 5)
+	red := RGB(255, 0, 0)
+	c.FillDisc(2, 2, 1.5, 2, red)
+
+	if got := c.Pixel(2, 3); got != red {
+		t.Fatalf("expected pixel above center set, got %v", got)
+	}
+	if got := c.Pixel(2, 5); got != ColorDefault {
+		t.Fatalf("expected pixel below maxY clear, got %v", got)
+	}
+}
+
+func TestFrame_DrawCanvas_HalfBlocks(t *testing.T) {
+	c := NewCanvas(2, 1)
+	sky, red := RGB(0, 0, 255), RGB(255, 0, 0)
+	c.Fill(sky)
+	c.SetPixel(0, 0, red)
+
+	f := NewFrame(3, 1)
+	f.DrawCanvas(c, 1, 0)
+
+	if got, want := f.At(1, 0), (Cell{Ch: '▀', Style: Style{Fg: red, Bg: sky}}); got != want {
+		t.Fatalf("cell 1: got %+v want %+v", got, want)
+	}
+	if got, want := f.At(2, 0), (Cell{Ch: ' ', Style: Style{Bg: sky}}); got != want {
+		t.Fatalf("cell 2: got %+v want %+v", got, want)
+	}
+	if got := f.At(0, 0); got != (Cell{Ch: ' '}) {
+		t.Fatalf("expected cell outside canvas untouched, got %+v", got)
+	}
+}
+
+func TestCanvas_FillColumn(t *testing.T) {
+	c := NewCanvas(3, 3)
+	white := RGB(255, 255, 255)
+	c.FillColumn(1, 1, 2, white)
+	for py := 0; py < 6; py++ {
+		want := ColorDefault
+		if py >= 2 {
+			want = white
+		}
+		if got := c.Pixel(1, py); got != want {
+			t.Fatalf("pixel row %d: got %v want %v", py, got, want)
+		}
+	}
+}
@@ -1,5 +1,5 @@
 // Package render provides a minimal ANSI terminal renderer built on a
-// fixed-size character grid.
+// fixed-size grid of styled Unicode cells.
 package render
 
 const (
@@ -7,63 +7,120 @@ const (
 	BlobHeight = 2
 )
 
-// Frame is a fixed-size 2D grid stored as a flat byte slice (row-major).
-// Cells must contain exactly Width*Height bytes.
+// Cell is one character of a frame and the style it is drawn with. Ch must
+// be a single-width rune.
+type Cell struct {
+	Ch    rune
+	Style Style
+}
+
+// Frame is a fixed-size 2D grid stored as a flat cell slice (row-major).
+// Cells must contain exactly Width*Height cells.
 type Frame struct {
 	Width  int
 	Height int
-	Cells  []byte
+	Cells  []Cell
 }
 
 func NewFrame(width, height int) *Frame {
 	f := &Frame{
 		Width:  width,
 		Height: height,
-		Cells:  make([]byte, width*height),
+		Cells:  make([]Cell, width*height),
 	}
 	f.Clear(' ')
 	return f
 }
 
-func (f *Frame) Clear(ch byte) {
+// Clear sets every cell to ch in the default style.
+func (f *Frame) Clear(ch rune) {
+	f.Fill(ch, Style{})
+}
+
+// Fill sets every cell to ch in style s.
+func (f *Frame) Fill(ch rune, s Style) {
 	for i := range f.Cells {
-		f.Cells[i] = ch
+		f.Cells[i] = Cell{Ch: ch, Style: s}
+	}
+}
+
+// Set changes the character at (x, y) and keeps its style.
+func (f *Frame) Set(x, y int, ch rune) {
+	if x < 0 || y < 0 || x >= f.Width || y >= f.Height {
+		return
 	}
+	f.Cells[y*f.Width+x].Ch = ch
 }
 
-func (f *Frame) Set(x, y int, ch byte) {
+// SetCell replaces the character and style at (x, y).
+func (f *Frame) SetCell(x, y int, c Cell) {
 	if x < 0 || y < 0 || x >= f.Width || y >= f.Height {
 		return
 	}
-	f.Cells[y*f.Width+x] = ch
+	f.Cells[y*f.Width+x] = c
 }
 
-func (f *Frame) DrawGround() {
+// At returns the cell at (x, y), or a zero Cell outside the frame.
+func (f *Frame) At(x, y int) Cell {
+	if x < 0 || y < 0 || x >= f.Width || y >= f.Height {
+		return Cell{}
+	}
+	return f.Cells[y*f.Width+x]
+}
+
+// Row returns the characters of row y.
+func (f *Frame) Row(y int) string {
+	r := make([]rune, f.Width)
+	for x := range r {
+		r[x] = f.Cells[y*f.Width+x].Ch
+	}
+	return string(r)
+}
+
+func (f *Frame) DrawGround(s Style) {
 	y := f.Height - 1
 	for x := 0; x < f.Width; x++ {
-		f.Set(x, y, '_')
+		f.SetCell(x, y, Cell{Ch: '_', Style: s})
 	}
 }
 
-func (f *Frame) DrawNet() {
+func (f *Frame) DrawNet(s Style) {
 	netX := f.Width / 2
 	// Draw a simple vertical net rising from the ground.
 	for y := f.Height - 2; y >= f.Height-8 && y >= 0; y-- {
-		f.Set(netX, y, '|')
+		f.SetCell(netX, y, Cell{Ch: '|', Style: s})
 	}
 }
 
-func (f *Frame) DrawBlob(x, y int) {
+func (f *Frame) DrawBlob(x, y int, s Style) {
 	// Simple 3x2 blob.
-	f.Set(x-1, y-1, 'O')
-	f.Set(x, y-1, 'O')
-	f.Set(x+1, y-1, 'O')
-	f.Set(x-1, y, 'O')
-	f.Set(x, y, 'O')
-	f.Set(x+1, y, 'O')
+	for dy := -1; dy <= 0; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			f.SetCell(x+dx, y+dy, Cell{Ch: 'O', Style: s})
+		}
+	}
 }
 
-func (f *Frame) DrawBall(x, y int) {
+func (f *Frame) DrawBall(x, y int, s Style) {
 	// 1x1 ball sprite at (x, y)
-	f.Set(x, y, '*')
+	f.SetCell(x, y, Cell{Ch: '*', Style: s})
+}
+
+// DrawText writes s starting at (x, y) in style st, clipped to the frame.
+func (f *Frame) DrawText(x, y int, s string, st Style) {
+	i := 0
+	for _, ch := range s {
+		f.SetCell(x+i, y, Cell{Ch: ch, Style: st})
+		i++
+	}
+}
+
+// Blit copies src into f with its top-left corner at (x, y). Cells that fall
+// outside f are dropped.
+func (f *Frame) Blit(src *Frame, x, y int) {
+	for sy := 0; sy < src.Height; sy++ {
+		for sx := 0; sx < src.Width; sx++ {
+			f.SetCell(x+sx, y+sy, src.Cells[sy*src.Width+sx])
+		}
+	}
 }
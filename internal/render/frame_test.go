@@ -10,8 +10,8 @@ func TestNewFrame_InitializesAndClears(t *testing.T) {
 	if len(f.Cells) != 12 {
 		t.Fatalf("unexpected Cells length: got %d want %d", len(f.Cells), 12)
 	}
-	for i, b := range f.Cells {
-		if b != ' ' {
+	for i, c := range f.Cells {
+		if b := c.Ch; b != ' ' {
 			t.Fatalf("cell %d: got %q want space", i, b)
 		}
 	}
@@ -20,8 +20,8 @@ func TestNewFrame_InitializesAndClears(t *testing.T) {
 func TestFrameClear_FillsAllCells(t *testing.T) {
 	f := NewFrame(3, 2)
 	f.Clear('x')
-	for i, b := range f.Cells {
-		if b != 'x' {
+	for i, c := range f.Cells {
+		if b := c.Ch; b != 'x' {
 			t.Fatalf("cell %d: got %q want %q", i, b, 'x')
 		}
 	}
@@ -30,7 +30,7 @@ func TestFrameClear_FillsAllCells(t *testing.T) {
 func TestFrameSet_InBounds(t *testing.T) {
 	f := NewFrame(3, 2)
 	f.Set(1, 1, 'A')
-	if got := f.Cells[1*f.Width+1]; got != 'A' {
+	if got := f.Cells[1*f.Width+1].Ch; got != 'A' {
 		t.Fatalf("expected set to write: got %q want %q", got, 'A')
 	}
 }
@@ -44,8 +44,8 @@ func TestFrameSet_OutOfBounds_DoesNotModify(t *testing.T) {
 	f.Set(3, 0, 'X')
 	f.Set(0, 2, 'X')
 
-	for i, b := range f.Cells {
-		if b != '.' {
+	for i, c := range f.Cells {
+		if b := c.Ch; b != '.' {
 			t.Fatalf("cell %d modified: got %q want %q", i, b, '.')
 		}
 	}
@@ -54,11 +54,11 @@ func TestFrameSet_OutOfBounds_DoesNotModify(t *testing.T) {
 func TestDrawGround_DrawsUnderscoresOnLastRow(t *testing.T) {
 	f := NewFrame(5, 4)
 	f.Clear(' ')
-	f.DrawGround()
+	f.DrawGround(Style{})
 
 	y := f.Height - 1
 	for x := 0; x < f.Width; x++ {
-		if got := f.Cells[y*f.Width+x]; got != '_' {
+		if got := f.Cells[y*f.Width+x].Ch; got != '_' {
 			t.Fatalf("ground at (%d,%d): got %q want %q", x, y, got, '_')
 		}
 	}
@@ -67,7 +67,7 @@ func TestDrawGround_DrawsUnderscoresOnLastRow(t *testing.T) {
 func TestDrawNet_DrawsVerticalLineAtMid(t *testing.T) {
 	f := NewFrame(10, 12)
 	f.Clear(' ')
-	f.DrawNet()
+	f.DrawNet(Style{})
 
 	netX := f.Width / 2
 	startY := f.Height - 2
@@ -76,14 +76,14 @@ func TestDrawNet_DrawsVerticalLineAtMid(t *testing.T) {
 		endY = 0
 	}
 	for y := startY; y >= endY; y-- {
-		if got := f.Cells[y*f.Width+netX]; got != '|' {
+		if got := f.Cells[y*f.Width+netX].Ch; got != '|' {
 			t.Fatalf("net at (%d,%d): got %q want %q", netX, y, got, '|')
 		}
 	}
 
 	// Ensure it does not draw on the ground row (height-1)
 	groundY := f.Height - 1
-	if got := f.Cells[groundY*f.Width+netX]; got == '|' {
+	if got := f.Cells[groundY*f.Width+netX].Ch; got == '|' {
 		t.Fatalf("net should not draw on ground row, but got %q at (%d,%d)", got, netX, groundY)
 	}
 }
@@ -91,7 +91,7 @@ func TestDrawNet_DrawsVerticalLineAtMid(t *testing.T) {
 func TestDrawBlob_Draws3x2Centered(t *testing.T) {
 	f := NewFrame(7, 5)
 	f.Clear(' ')
-	f.DrawBlob(3, 3)
+	f.DrawBlob(3, 3, Style{})
 
 	points := [][2]int{
 		{2, 2}, {3, 2}, {4, 2},
@@ -99,7 +99,7 @@ func TestDrawBlob_Draws3x2Centered(t *testing.T) {
 	}
 	for _, p := range points {
 		x, y := p[0], p[1]
-		if got := f.Cells[y*f.Width+x]; got != 'O' {
+		if got := f.Cells[y*f.Width+x].Ch; got != 'O' {
 			t.Fatalf("blob at (%d,%d): got %q want %q", x, y, got, 'O')
 		}
 	}
@@ -112,22 +112,22 @@ func TestDrawBlob_ClipsAtEdges(t *testing.T) {
 	// Center at (0,0) would normally attempt to draw negative coords;
 	// only (0,0) and (1,0) and (0,1) and (1,1) are possible, but due to 3x2 shape
 	// with offsets, we expect only positions that land in-bounds to be written.
-	f.DrawBlob(0, 0)
+	f.DrawBlob(0, 0, Style{})
 
 	// In-bounds writes possible from:
 	// y-1 = -1 => ignored
 	// y   = 0  => x-1=-1 ignored, x=0 set, x+1=1 set
-	if got := f.Cells[0*f.Width+0]; got != 'O' {
+	if got := f.Cells[0*f.Width+0].Ch; got != 'O' {
 		t.Fatalf("expected in-bounds blob write at (0,0): got %q want %q", got, 'O')
 	}
-	if got := f.Cells[0*f.Width+1]; got != 'O' {
+	if got := f.Cells[0*f.Width+1].Ch; got != 'O' {
 		t.Fatalf("expected in-bounds blob write at (1,0): got %q want %q", got, 'O')
 	}
 	// Bottom row should remain '.' because y=0 only affects y=0 for this call (y-1 ignored)
-	if got := f.Cells[1*f.Width+0]; got != '.' {
+	if got := f.Cells[1*f.Width+0].Ch; got != '.' {
 		t.Fatalf("unexpected write at (0,1): got %q want %q", got, '.')
 	}
-	if got := f.Cells[1*f.Width+1]; got != '.' {
+	if got := f.Cells[1*f.Width+1].Ch; got != '.' {
 		t.Fatalf("unexpected write at (1,1): got %q want %q", got, '.')
 	}
 }
@@ -135,14 +135,75 @@ func TestDrawBlob_ClipsAtEdges(t *testing.T) {
 func TestDrawBall_DrawsAsterisk(t *testing.T) {
 	f := NewFrame(3, 3)
 	f.Clear(' ')
-	f.DrawBall(1, 2)
-	if got := f.Cells[2*f.Width+1]; got != '*' {
+	f.DrawBall(1, 2, Style{})
+	if got := f.Cells[2*f.Width+1].Ch; got != '*' {
 		t.Fatalf("ball at (1,2): got %q want %q", got, '*')
 	}
 }
 
 func TestDrawGroundAndNet_DoNotPanicOnSmallFrames(t *testing.T) {
 	f := NewFrame(1, 1)
-	f.DrawGround()
-	f.DrawNet() // should clip via Set without panic
+	f.DrawGround(Style{})
+	f.DrawNet(Style{}) // should clip via Set without panic
+}
+
+func TestDrawText_WritesAndClips(t *testing.T) {
+	f := NewFrame(4, 1)
+	f.DrawText(1, 0, "abcdef", Style{})
+	if got := f.Row(0); got != " abc" {
+		t.Fatalf("got %q want %q", got, " abc")
+	}
+}
+
+func TestBlit_CopiesAtOffsetAndClips(t *testing.T) {
+	src := NewFrame(2, 2)
+	src.DrawText(0, 0, "ab", Style{})
+	src.DrawText(0, 1, "cd", Style{})
+
+	f := NewFrame(3, 3)
+	f.Clear('.')
+	f.Blit(src, 2, 1)
+
+	want := []string{"...", "..a", "..c"}
+	for y, row := range want {
+		if got := f.Row(y); got != row {
+			t.Fatalf("row %d: got %q want %q", y, got, row)
+		}
+	}
+}
+
+func TestFrameSet_KeepsStyle(t *testing.T) {
+	f := NewFrame(1, 1)
+	st := Style{Fg: RGB(1, 2, 3), Attr: AttrDim}
+	f.Fill('.', st)
+	f.Set(0, 0, 'x')
+	if got := f.At(0, 0); got != (Cell{Ch: 'x', Style: st}) {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestDrawBlob_AppliesStyle(t *testing.T) {
+	f := NewFrame(3, 2)
+	st := Style{Fg: RGB(200, 0, 0)}
+	f.DrawBlob(1, 1, st)
+	for i, c := range f.Cells {
+		if c.Style != st {
+			t.Fatalf("cell %d: got style %+v want %+v", i, c.Style, st)
+		}
+	}
+}
+
+func TestFrameAt_OutOfBoundsIsZero(t *testing.T) {
+	f := NewFrame(1, 1)
+	if got := f.At(5, 5); got != (Cell{}) {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestDrawText_Runes(t *testing.T) {
+	f := NewFrame(3, 1)
+	f.DrawText(0, 0, "★b", Style{})
+	if got := f.Row(0); got != "★b " {
+		t.Fatalf("got %q want %q", got, "★b ")
+	}
 }
@@ -1,5 +1,5 @@
 // Package render provides a minimal ANSI terminal renderer built on a
-// fixed-size character grid.
+// fixed-size grid of styled Unicode cells.
 package render
 
 import (
@@ -8,15 +8,27 @@ import (
 	"io"
 )
 
+// Renderer draws frames to a terminal. After the first full repaint it only
+// emits cursor moves and the runs of cells that changed since the previous
+// frame, with SGR sequences for styles in the selected ColorMode.
 type Renderer struct {
 	out    io.Writer
 	bw     *bufio.Writer
 	width  int
 	height int
 
-	clearedOnce bool
+	// prev is the frame currently on screen; nil forces a full repaint.
+	prev *Frame
+
+	mode ColorMode
+	// pen is the style the terminal is currently set to draw with.
+	pen Style
 }
 
+// mergeGap is the largest run of unchanged cells rewritten to join two
+// changed runs on a row; a cursor move costs about as many bytes.
+const mergeGap = 4
+
 func NewRenderer(out io.Writer, width, height int) *Renderer {
 	return &Renderer{
 		out:    out,
@@ -26,6 +38,25 @@ func NewRenderer(out io.Writer, width, height int) *Renderer {
 	}
 }
 
+// Resize changes the expected frame size and forces a full repaint.
+func (r *Renderer) Resize(width, height int) {
+	r.width, r.height = width, height
+	r.prev = nil
+}
+
+// SetColorMode selects how styles are emitted and forces a full repaint.
+// New renderers use ColorNone.
+func (r *Renderer) SetColorMode(m ColorMode) {
+	r.mode = m
+	r.prev = nil
+}
+
+// Invalidate forces the next Draw to clear the screen and repaint every
+// cell, e.g. after something else wrote to the terminal.
+func (r *Renderer) Invalidate() {
+	r.prev = nil
+}
+
 func (r *Renderer) HideCursor() error {
 	if _, err := fmt.Fprint(r.bw, "\x1b[?25l"); err != nil {
 		return err
@@ -40,6 +71,17 @@ func (r *Renderer) ShowCursor() error {
 	return r.bw.Flush()
 }
 
+// Reset restores default colors, clears the screen and shows the cursor,
+// leaving the terminal as it was before the first Draw.
+func (r *Renderer) Reset() error {
+	if _, err := fmt.Fprint(r.bw, "\x1b[0m\x1b[2J\x1b[H\x1b[?25h"); err != nil {
+		return err
+	}
+	r.pen = Style{}
+	r.prev = nil
+	return r.bw.Flush()
+}
+
 func (r *Renderer) Draw(f *Frame) error {
 	if f.Width != r.width || f.Height != r.height {
 		return fmt.Errorf(
@@ -48,20 +90,39 @@ func (r *Renderer) Draw(f *Frame) error {
 		)
 	}
 
-	// Clear once, then redraw from home to avoid flicker.
-	if !r.clearedOnce {
-		if _, err := fmt.Fprint(r.bw, "\x1b[2J"); err != nil {
+	var err error
+	if r.prev == nil {
+		err = r.drawFull(f)
+	} else {
+		err = r.drawDiff(f)
+	}
+	if err != nil {
+		return err
+	}
+
+	if r.prev == nil {
+		r.prev = NewFrame(f.Width, f.Height)
+	}
+	copy(r.prev.Cells, f.Cells)
+	return r.bw.Flush()
+}
+
+// drawFull clears the screen and writes every row from the top left.
+func (r *Renderer) drawFull(f *Frame) error {
+	// Reset first: the clear paints with the current background color.
+	if r.pen != (Style{}) {
+		if _, err := r.bw.WriteString("\x1b[0m"); err != nil {
 			return err
 		}
-		r.clearedOnce = true
+		r.pen = Style{}
 	}
-	if _, err := fmt.Fprint(r.bw, "\x1b[H"); err != nil {
+	if _, err := fmt.Fprint(r.bw, "\x1b[2J\x1b[H"); err != nil {
 		return err
 	}
 
 	for y := 0; y < r.height; y++ {
 		row := f.Cells[y*r.width : (y+1)*r.width]
-		if _, err := r.bw.Write(row); err != nil {
+		if err := r.writeCells(row); err != nil {
 			return err
 		}
 		// IMPORTANT: force carriage return so next row starts at column 1.
@@ -71,6 +132,59 @@ func (r *Renderer) Draw(f *Frame) error {
 			}
 		}
 	}
+	return nil
+}
+
+// drawDiff writes only the cells that differ from r.prev. Changed runs
+// separated by fewer than mergeGap unchanged cells are written as one.
+func (r *Renderer) drawDiff(f *Frame) error {
+	for y := 0; y < r.height; y++ {
+		row := f.Cells[y*r.width : (y+1)*r.width]
+		old := r.prev.Cells[y*r.width : (y+1)*r.width]
+
+		x := 0
+		for x < r.width {
+			if row[x] == old[x] {
+				x++
+				continue
+			}
+			start, end := x, x+1
+			for i := end; i < r.width && i-end < mergeGap; i++ {
+				if row[i] != old[i] {
+					end = i + 1
+				}
+			}
+
+			// Cursor positions are 1-based.
+			if _, err := fmt.Fprintf(r.bw, "\x1b[%d;%dH", y+1, start+1); err != nil {
+				return err
+			}
+			if err := r.writeCells(row[start:end]); err != nil {
+				return err
+			}
+			x = end
+		}
+	}
+	return nil
+}
 
-	return r.bw.Flush()
+// writeCells writes cells at the cursor, switching styles only when they
+// change.
+func (r *Renderer) writeCells(cells []Cell) error {
+	for _, c := range cells {
+		st := c.Style
+		if r.mode == ColorNone {
+			st = Style{Attr: st.Attr}
+		}
+		if st != r.pen {
+			if _, err := r.bw.WriteString(sgr(st, r.mode)); err != nil {
+				return err
+			}
+			r.pen = st
+		}
+		if _, err := r.bw.WriteRune(c.Ch); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -6,6 +6,15 @@ import (
 	"testing"
 )
 
+// textFrame builds a frame from row-major characters in the default style.
+func textFrame(width, height int, s string) *Frame {
+	f := NewFrame(width, height)
+	for i, ch := range []rune(s) {
+		f.Cells[i].Ch = ch
+	}
+	return f
+}
+
 func TestNewRenderer_SetsDimensions(t *testing.T) {
 	var out bytes.Buffer
 	r := NewRenderer(&out, 10, 5)
@@ -46,7 +55,7 @@ func TestRenderer_Draw_SizeMismatch(t *testing.T) {
 	f := &Frame{
 		Width:  5,
 		Height: 2,
-		Cells:  make([]byte, 10),
+		Cells:  make([]Cell, 10),
 	}
 
 	err := r.Draw(f)
@@ -61,15 +70,11 @@ func TestRenderer_Draw_SizeMismatch(t *testing.T) {
 	}
 }
 
-func TestRenderer_Draw_ClearsOnceAndHomesEveryDraw(t *testing.T) {
+func TestRenderer_Draw_ClearsOnceThenSkipsUnchangedFrames(t *testing.T) {
 	var out bytes.Buffer
 	r := NewRenderer(&out, 3, 2)
 
-	f := &Frame{
-		Width:  3,
-		Height: 2,
-		Cells:  []byte("abcdef"),
-	}
+	f := textFrame(3, 2, "abcdef")
 
 	if err := r.Draw(f); err != nil {
 		t.Fatalf("Draw(1) error: %v", err)
@@ -84,10 +89,108 @@ func TestRenderer_Draw_ClearsOnceAndHomesEveryDraw(t *testing.T) {
 	if err := r.Draw(f); err != nil {
 		t.Fatalf("Draw(2) error: %v", err)
 	}
-	got2 := out.String()
-	want2 := "\x1b[H" + "abc\r\ndef"
-	if got2 != want2 {
-		t.Fatalf("Draw(2) output mismatch:\n got: %q\nwant: %q", got2, want2)
+	if got2 := out.String(); got2 != "" {
+		t.Fatalf("Draw(2) expected no output for unchanged frame, got %q", got2)
+	}
+}
+
+func TestRenderer_Draw_EmitsOnlyChangedRuns(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out, 12, 2)
+
+	f := NewFrame(12, 2)
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw(1) error: %v", err)
+	}
+
+	f.Set(1, 0, 'a')
+	f.Set(10, 0, 'b')
+	f.Set(4, 1, 'c')
+	out.Reset()
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw(2) error: %v", err)
+	}
+	want := "\x1b[1;2Ha" + "\x1b[1;11Hb" + "\x1b[2;5Hc"
+	if got := out.String(); got != want {
+		t.Fatalf("diff output mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestRenderer_Draw_MergesNearbyChanges(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out, 8, 1)
+
+	f := NewFrame(8, 1)
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw(1) error: %v", err)
+	}
+
+	f.Set(1, 0, 'x')
+	f.Set(3, 0, 'y')
+	out.Reset()
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw(2) error: %v", err)
+	}
+	want := "\x1b[1;2Hx y"
+	if got := out.String(); got != want {
+		t.Fatalf("merged output mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestRenderer_Invalidate_ForcesFullRepaint(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out, 2, 1)
+
+	f := textFrame(2, 1, "ok")
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw(1) error: %v", err)
+	}
+
+	r.Invalidate()
+	out.Reset()
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw(2) error: %v", err)
+	}
+	if got, want := out.String(), "\x1b[2J\x1b[Hok"; got != want {
+		t.Fatalf("output mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestRenderer_Resize_AcceptsNewSizeWithFullRepaint(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out, 2, 1)
+	if err := r.Draw(NewFrame(2, 1)); err != nil {
+		t.Fatalf("Draw(1) error: %v", err)
+	}
+
+	r.Resize(3, 2)
+	out.Reset()
+	f := textFrame(3, 2, "abcdef")
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw after resize error: %v", err)
+	}
+	if got, want := out.String(), "\x1b[2J\x1b[Habc\r\ndef"; got != want {
+		t.Fatalf("output mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestRenderer_Draw_DoesNotAliasCallerFrame(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out, 2, 1)
+
+	f := NewFrame(2, 1)
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw(1) error: %v", err)
+	}
+
+	// Mutating the same frame after drawing must still produce a diff.
+	f.Set(0, 0, 'z')
+	out.Reset()
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw(2) error: %v", err)
+	}
+	if got, want := out.String(), "\x1b[1;1Hz"; got != want {
+		t.Fatalf("output mismatch:\n got: %q\nwant: %q", got, want)
 	}
 }
 
@@ -95,11 +198,7 @@ func TestRenderer_Draw_NoTrailingNewlineOnLastRow(t *testing.T) {
 	var out bytes.Buffer
 	r := NewRenderer(&out, 2, 2)
 
-	f := &Frame{
-		Width:  2,
-		Height: 2,
-		Cells:  []byte("wxyz"),
-	}
+	f := textFrame(2, 2, "wxyz")
 
 	if err := r.Draw(f); err != nil {
 		t.Fatalf("Draw error: %v", err)
@@ -113,3 +212,117 @@ func TestRenderer_Draw_NoTrailingNewlineOnLastRow(t *testing.T) {
 		t.Fatalf("expected no trailing CRLF, got %q", out.String())
 	}
 }
+
+func TestRenderer_Draw_ColorNoneEmitsAttributesOnly(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out, 2, 1)
+
+	f := NewFrame(2, 1)
+	f.SetCell(0, 0, Cell{Ch: 'a', Style: Style{Fg: RGB(255, 0, 0), Attr: AttrBold}})
+	f.SetCell(1, 0, Cell{Ch: 'b', Style: Style{Bg: RGB(0, 0, 255)}})
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw error: %v", err)
+	}
+	want := "\x1b[2J\x1b[H" + "\x1b[0;1ma" + "\x1b[0mb"
+	if got := out.String(); got != want {
+		t.Fatalf("output mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestRenderer_Draw_TrueColorSwitchesOnlyOnChange(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out, 3, 1)
+	r.SetColorMode(ColorTrue)
+
+	red := Style{Fg: RGB(255, 0, 0)}
+	f := NewFrame(3, 1)
+	f.SetCell(0, 0, Cell{Ch: 'a', Style: red})
+	f.SetCell(1, 0, Cell{Ch: 'b', Style: red})
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw error: %v", err)
+	}
+	want := "\x1b[2J\x1b[H" + "\x1b[0;38;2;255;0;0mab" + "\x1b[0m "
+	if got := out.String(); got != want {
+		t.Fatalf("output mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestRenderer_Draw_StyleOnlyChangeIsRedrawn(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out, 1, 1)
+	r.SetColorMode(Color256)
+
+	f := textFrame(1, 1, "x")
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw(1) error: %v", err)
+	}
+	f.SetCell(0, 0, Cell{Ch: 'x', Style: Style{Bg: RGB(255, 0, 0)}})
+	out.Reset()
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw(2) error: %v", err)
+	}
+	if got, want := out.String(), "\x1b[1;1H\x1b[0;48;5;196mx"; got != want {
+		t.Fatalf("output mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestRenderer_FullRepaint_ResetsStyleBeforeClear(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out, 1, 1)
+	r.SetColorMode(Color16)
+
+	f := NewFrame(1, 1)
+	f.SetCell(0, 0, Cell{Ch: 'x', Style: Style{Bg: RGB(0, 0, 238)}})
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw(1) error: %v", err)
+	}
+	r.Invalidate()
+	out.Reset()
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw(2) error: %v", err)
+	}
+	if got, want := out.String(), "\x1b[0m\x1b[2J\x1b[H\x1b[0;44mx"; got != want {
+		t.Fatalf("output mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestRenderer_Reset_RestoresTerminal(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out, 1, 1)
+	r.SetColorMode(ColorTrue)
+
+	f := NewFrame(1, 1)
+	f.SetCell(0, 0, Cell{Ch: 'x', Style: Style{Bg: RGB(9, 9, 9)}})
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw error: %v", err)
+	}
+	out.Reset()
+	if err := r.Reset(); err != nil {
+		t.Fatalf("Reset error: %v", err)
+	}
+	if got, want := out.String(), "\x1b[0m\x1b[2J\x1b[H\x1b[?25h"; got != want {
+		t.Fatalf("output mismatch:\n got: %q\nwant: %q", got, want)
+	}
+
+	// The next draw starts over with a full repaint from the default pen.
+	out.Reset()
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw error: %v", err)
+	}
+	if got, want := out.String(), "\x1b[2J\x1b[H\x1b[0;48;2;9;9;9mx"; got != want {
+		t.Fatalf("output mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestRenderer_Draw_WritesUTF8(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out, 2, 1)
+
+	f := textFrame(2, 1, "▀é")
+	if err := r.Draw(f); err != nil {
+		t.Fatalf("Draw error: %v", err)
+	}
+	if got, want := out.String(), "\x1b[2J\x1b[H▀é"; got != want {
+		t.Fatalf("output mismatch:\n got: %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,178 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Color is a 24-bit RGB color, or ColorDefault for the terminal's own
+// foreground/background.
+type Color uint32
+
+const ColorDefault Color = 0
+
+// colorSet marks a Color as an explicit RGB value so black is not default.
+const colorSet = 1 << 24
+
+func RGB(r, g, b uint8) Color {
+	return Color(colorSet | uint32(r)<<16 | uint32(g)<<8 | uint32(b))
+}
+
+// RGB returns the components of c; ok is false for ColorDefault.
+func (c Color) RGB() (r, g, b uint8, ok bool) {
+	if c&colorSet == 0 {
+		return 0, 0, 0, false
+	}
+	return uint8(c >> 16), uint8(c >> 8), uint8(c), true
+}
+
+// Attr is a bit set of text attributes.
+type Attr uint8
+
+const (
+	AttrBold Attr = 1 << iota
+	AttrDim
+)
+
+// Style is the look of a cell. The zero Style is the terminal default.
+type Style struct {
+	Fg, Bg Color
+	Attr   Attr
+}
+
+// ColorMode is how many colors the terminal can show.
+type ColorMode uint8
+
+const (
+	// ColorNone emits attributes only, for NO_COLOR or dumb terminals.
+	ColorNone ColorMode = iota
+	Color16
+	Color256
+	ColorTrue
+)
+
+// DetectColorMode picks a color mode from the environment, honoring the
+// NO_COLOR convention (https://no-color.org).
+func DetectColorMode(getenv func(string) string) ColorMode {
+	if getenv("NO_COLOR") != "" {
+		return ColorNone
+	}
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorTrue
+	}
+	term := getenv("TERM")
+	switch {
+	case term == "" || term == "dumb":
+		return ColorNone
+	case strings.Contains(term, "256color"):
+		return Color256
+	case strings.Contains(term, "truecolor") || strings.Contains(term, "direct"):
+		return ColorTrue
+	}
+	return Color16
+}
+
+// sgr returns the escape sequence that switches the terminal from any
+// state to s under mode.
+func sgr(s Style, mode ColorMode) string {
+	var b strings.Builder
+	b.WriteString("\x1b[0")
+	if s.Attr&AttrBold != 0 {
+		b.WriteString(";1")
+	}
+	if s.Attr&AttrDim != 0 {
+		b.WriteString(";2")
+	}
+	if mode != ColorNone {
+		writeColor(&b, s.Fg, mode, false)
+		writeColor(&b, s.Bg, mode, true)
+	}
+	b.WriteByte('m')
+	return b.String()
+}
+
+func writeColor(b *strings.Builder, c Color, mode ColorMode, bg bool) {
+	r, g, bl, ok := c.RGB()
+	if !ok {
+		return
+	}
+	switch mode {
+	case ColorTrue:
+		if bg {
+			b.WriteString(";48;2;")
+		} else {
+			b.WriteString(";38;2;")
+		}
+		b.WriteString(strconv.Itoa(int(r)) + ";" + strconv.Itoa(int(g)) + ";" + strconv.Itoa(int(bl)))
+	case Color256:
+		if bg {
+			b.WriteString(";48;5;")
+		} else {
+			b.WriteString(";38;5;")
+		}
+		b.WriteString(strconv.Itoa(to256(r, g, bl)))
+	case Color16:
+		n := to16(r, g, bl)
+		base := 30
+		if bg {
+			base = 40
+		}
+		if n >= 8 {
+			base += 60
+			n -= 8
+		}
+		b.WriteString(";" + strconv.Itoa(base+n))
+	}
+}
+
+// to256 maps a color onto the 6x6x6 cube of the 256-color palette.
+func to256(r, g, b uint8) int {
+	q := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+	return 16 + 36*q(r) + 6*q(g) + q(b)
+}
+
+// ansi16 approximates the xterm defaults of the 16 basic colors.
+var ansi16 = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// to16 returns the index of the basic color nearest to (r, g, b).
+func to16(r, g, b uint8) int {
+	best, bestD := 0, -1
+	for i, c := range ansi16 {
+		dr, dg, db := int(r)-c[0], int(g)-c[1], int(b)-c[2]
+		d := dr*dr + dg*dg + db*db
+		if bestD < 0 || d < bestD {
+			best, bestD = i, d
+		}
+	}
+	return best
+}
+
+// Theme is the palette used to draw a match.
+type Theme struct {
+	Sky   Style
+	Sand  Style
+	Net   Style
+	Ball  Style
+	Blobs [2]Style
+	Text  Style
+}
+
+// DefaultTheme is a daytime beach: blue sky, yellow sand, a white net, a
+// red and a green blob and an orange ball.
+func DefaultTheme() Theme {
+	sky := RGB(40, 90, 160)
+	return Theme{
+		Sky:   Style{Bg: sky},
+		Sand:  Style{Fg: RGB(120, 90, 30), Bg: RGB(230, 200, 110)},
+		Net:   Style{Fg: RGB(245, 245, 245), Bg: sky, Attr: AttrBold},
+		Ball:  Style{Fg: RGB(255, 170, 40), Bg: sky, Attr: AttrBold},
+		Blobs: [2]Style{{Fg: RGB(230, 60, 60), Bg: sky, Attr: AttrBold}, {Fg: RGB(70, 200, 90), Bg: sky, Attr: AttrBold}},
+		Text:  Style{Fg: RGB(255, 255, 255), Bg: sky, Attr: AttrBold},
+	}
+}
@@ -0,0 +1,51 @@
+package render
+
+import "testing"
+
+func TestColor_DefaultVersusBlack(t *testing.T) {
+	if _, _, _, ok := ColorDefault.RGB(); ok {
+		t.Fatalf("expected ColorDefault to have no RGB value")
+	}
+	r, g, b, ok := RGB(0, 0, 0).RGB()
+	if !ok || r != 0 || g != 0 || b != 0 {
+		t.Fatalf("expected explicit black, got %d,%d,%d ok=%v", r, g, b, ok)
+	}
+}
+
+func TestDetectColorMode(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want ColorMode
+	}{
+		{map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1"}, ColorNone},
+		{map[string]string{"TERM": "xterm", "COLORTERM": "truecolor"}, ColorTrue},
+		{map[string]string{"TERM": "xterm-256color"}, Color256},
+		{map[string]string{"TERM": "xterm"}, Color16},
+		{map[string]string{"TERM": "dumb"}, ColorNone},
+		{map[string]string{}, ColorNone},
+	}
+	for _, tt := range tests {
+		got := DetectColorMode(func(k string) string { return tt.env[k] })
+		if got != tt.want {
+			t.Fatalf("%v: got %d want %d", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestSGR_Modes(t *testing.T) {
+	s := Style{Fg: RGB(255, 0, 0), Bg: RGB(0, 0, 0), Attr: AttrBold | AttrDim}
+	tests := []struct {
+		mode ColorMode
+		want string
+	}{
+		{ColorNone, "\x1b[0;1;2m"},
+		{Color16, "\x1b[0;1;2;91;40m"},
+		{Color256, "\x1b[0;1;2;38;5;196;48;5;16m"},
+		{ColorTrue, "\x1b[0;1;2;38;2;255;0;0;48;2;0;0;0m"},
+	}
+	for _, tt := range tests {
+		if got := sgr(s, tt.mode); got != tt.want {
+			t.Fatalf("mode %d: got %q want %q", tt.mode, got, tt.want)
+		}
+	}
+}
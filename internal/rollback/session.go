@@ -0,0 +1,466 @@
+// Package rollback plays one match on two machines the way GGPO does. Each
+// peer runs the same deterministic simulation, applies its own players'
+// input right away and guesses the other peer's. When the real input
+// arrives and the guess was wrong, the peer rewinds to the state it saved
+// before that frame and simulates forward again, so a late input costs a
+// correction rather than a wait.
+//
+// The peers exchange small packets over a Link. Every packet repeats the
+// inputs the other peer has not acknowledged yet, so lost and reordered
+// packets do no harm.
+package rollback
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"terminalvolley/internal/engine"
+)
+
+// Link carries packets between the two peers. Packets may be lost,
+// delayed or reordered, but not corrupted.
+type Link interface {
+	// Send sends a packet without waiting for it to arrive.
+	Send(packet []byte) error
+	// Receive returns the next packet that has arrived, if any, without
+	// waiting.
+	Receive() ([]byte, bool)
+}
+
+// DefaultMaxRollback is how many frames a peer runs ahead of the last
+// input it has from the other peer before it waits for it.
+const DefaultMaxRollback = 60
+
+const (
+	// syncInterval is how many frames apart the peers compare checksums
+	// of their games.
+	syncInterval = 50
+	// waitInterval is how many frames apart a peer that runs ahead holds
+	// back a frame to let the other catch up.
+	waitInterval = 10
+)
+
+// ErrDesync is reported when the peers' games have diverged, which means
+// the simulation was not deterministic across the two machines.
+var ErrDesync = errors.New("rollback: games diverged")
+
+// Stats counts what a session did to hide the network.
+type Stats struct {
+	// Rollbacks counts rewinds, Resimulated the frames simulated again
+	// and Longest the most frames a single rewind went back.
+	Rollbacks   int
+	Resimulated int
+	Longest     int
+	// Stalls counts steps spent waiting for the remote peer's input and
+	// Waits steps held back to let a remote peer that runs behind catch
+	// up.
+	Stalls int
+	Waits  int
+}
+
+// Session is one peer's side of a match. The peer plays the players of
+// one team: peer 0 plays Team1 and peer 1 plays Team2.
+type Session struct {
+	g           *engine.Game
+	link        Link
+	peer        int
+	delay       int
+	maxRollback int
+
+	// frame is the next frame to simulate.
+	frame int
+	// inputs holds every player's input per frame, from frame base on.
+	// Local input is known for frames < localFrames and the remote's for
+	// frames < remoteFrames; later remote entries hold the guess the frame
+	// was simulated with.
+	base         int
+	inputs       [][]engine.Input
+	localFrames  int
+	remoteFrames int
+	// acked is how many frames of local input the remote has confirmed.
+	acked int
+	// states[f%len(states)] is the game before frame f.
+	states []engine.Snapshot
+	// rewind is the first frame simulated with a wrong guess, or -1.
+	rewind int
+
+	// The remote's frame and how far it runs ahead of us, as of its last
+	// packet, for keeping the peers in step.
+	heard           bool
+	remoteFrame     int
+	remoteAdvantage int
+	lastWait        int
+
+	// Checksums of the game at every syncInterval frames.
+	summed     int
+	lastSum    uint64
+	sums       map[int]uint64
+	remoteSums map[int]uint64
+	checked    int
+
+	stats Stats
+	err   error
+}
+
+// Option configures a Session.
+type Option func(*Session)
+
+// WithInputDelay delays local input by n frames. A delay that covers the
+// one-way network latency leaves nothing to predict; a shorter one trades
+// rollbacks for responsiveness.
+func WithInputDelay(n int) Option {
+	return func(s *Session) { s.delay = max(n, 0) }
+}
+
+// WithMaxRollback sets how many frames a peer runs ahead of the remote's
+// input before it waits; the default is DefaultMaxRollback.
+func WithMaxRollback(n int) Option {
+	return func(s *Session) { s.maxRollback = max(n, 1) }
+}
+
+// New starts a session for peer 0 or 1 on g, which must be a new game
+// set up exactly like the remote peer's.
+func New(g *engine.Game, peer int, link Link, opts ...Option) *Session {
+	s := &Session{
+		g:           g,
+		link:        link,
+		peer:        peer,
+		maxRollback: DefaultMaxRollback,
+		rewind:      -1,
+		lastWait:    -waitInterval,
+		sums:        map[int]uint64{},
+		remoteSums:  map[int]uint64{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.states = make([]engine.Snapshot, s.maxRollback+2)
+	// Nothing is pressed during the first frames of input delay.
+	s.localFrames = s.delay
+	if s.delay > 0 {
+		s.ensure(s.delay - 1)
+	}
+	return s
+}
+
+// Game returns the game the session simulates. It is rewound now and
+// then, so it may jump; it must only be changed through Step.
+func (s *Session) Game() *engine.Game { return s.g }
+
+// Local reports whether player i is played on this peer.
+func (s *Session) Local(i int) bool { return s.g.Player(i).Team == s.peer }
+
+// Frame returns how many frames have been simulated.
+func (s *Session) Frame() int { return s.frame }
+
+// Confirmed returns how many frames have the remote's real input.
+func (s *Session) Confirmed() int { return s.remoteFrames }
+
+// Stats returns what the session did so far.
+func (s *Session) Stats() Stats { return s.stats }
+
+// Err returns why the session broke down: the link failed, the remote
+// sent garbage or the games diverged.
+func (s *Session) Err() error { return s.err }
+
+// Step advances the match by one frame. local holds what each local
+// player does now, indexed by player; entries of remote players are
+// ignored. It takes effect after the input delay, and a serve while the
+// match is over starts a rematch. Step reports whether it simulated a
+// frame: it waits while the remote's input is too far behind, or now
+// and then to let a remote that runs behind catch up.
+func (s *Session) Step(local []engine.Input) bool {
+	s.receive()
+	s.rollback()
+	stepped := false
+	switch {
+	case s.err != nil:
+	case s.frame-s.remoteFrames >= s.maxRollback:
+		s.stats.Stalls++
+	case s.ahead():
+		s.stats.Waits++
+	default:
+		s.record(local)
+		s.simulate(s.frame)
+		s.frame++
+		stepped = true
+	}
+	s.checksum()
+	s.send()
+	s.trim()
+	return stepped
+}
+
+// record stores local as the local players' input for the frame the
+// input delay puts it in.
+func (s *Session) record(local []engine.Input) {
+	f := s.localFrames
+	in := s.ensure(f)
+	for i := range in {
+		if s.Local(i) && i < len(local) {
+			in[i] = local[i]
+		}
+	}
+	s.localFrames++
+}
+
+// ensure makes room for the inputs of frame f and returns them.
+func (s *Session) ensure(f int) []engine.Input {
+	for s.base+len(s.inputs) <= f {
+		s.inputs = append(s.inputs, make([]engine.Input, s.g.NumPlayers()))
+	}
+	return s.inputs[f-s.base]
+}
+
+// simulate saves the game and plays frame f, guessing the remote's input
+// if it has not arrived.
+func (s *Session) simulate(f int) {
+	in := s.ensure(f)
+	if f >= s.remoteFrames {
+		s.predict(in)
+	}
+	s.states[f%len(s.states)] = s.g.Snapshot()
+	if s.g.Over() {
+		for _, x := range in {
+			if x.Serve {
+				s.g.Reset()
+				break
+			}
+		}
+	}
+	for i, x := range in {
+		s.g.SetInput(i, x)
+	}
+	s.g.Step()
+}
+
+// predict guesses that the remote players keep holding what they held in
+// the last frame known. Serves are one-shot and never guessed.
+func (s *Session) predict(in []engine.Input) {
+	var last []engine.Input
+	if s.remoteFrames > 0 {
+		last = s.inputs[s.remoteFrames-1-s.base]
+	}
+	for i := range in {
+		if s.Local(i) {
+			continue
+		}
+		var guess engine.Input
+		if last != nil {
+			guess = last[i]
+			guess.Serve = false
+		}
+		in[i] = guess
+	}
+}
+
+// rollback rewinds to the first frame simulated with a wrong guess and
+// plays up to the current frame again.
+func (s *Session) rollback() {
+	from := s.rewind
+	if from < 0 {
+		return
+	}
+	s.rewind = -1
+	if err := s.g.Restore(s.states[from%len(s.states)]); err != nil {
+		s.err = err
+		return
+	}
+	for f := from; f < s.frame; f++ {
+		s.simulate(f)
+	}
+	n := s.frame - from
+	s.stats.Rollbacks++
+	s.stats.Resimulated += n
+	s.stats.Longest = max(s.stats.Longest, n)
+}
+
+// ahead reports whether this peer should hold back a frame because it
+// runs ahead of the remote. Both peers see the other's frame as old as
+// the latency, so half the difference of their views is the real lead.
+func (s *Session) ahead() bool {
+	if !s.heard || s.frame-s.lastWait < waitInterval {
+		return false
+	}
+	lead := s.frame - s.remoteFrame
+	if (lead-s.remoteAdvantage)/2 < 1 {
+		return false
+	}
+	s.lastWait = s.frame
+	return true
+}
+
+// packet is what the peers send each other every step.
+type packet struct {
+	// Frame is the sender's next frame and Advantage how far it ran ahead
+	// of the receiver's last packet.
+	Frame     int `json:"frame"`
+	Advantage int `json:"adv"`
+	// Ack tells the receiver that the sender has its input for the
+	// frames before Ack.
+	Ack int `json:"ack"`
+	// Inputs holds the sender's players' input for the frames from
+	// Start on, 4 bits per player.
+	Start  int   `json:"start"`
+	Inputs []int `json:"inputs"`
+	// Sum is the checksum of the sender's game before frame SumFrame,
+	// once it has one.
+	SumFrame int    `json:"sumFrame,omitempty"`
+	Sum      uint64 `json:"sum,omitempty"`
+}
+
+func (s *Session) send() {
+	p := packet{
+		Frame:     s.frame,
+		Advantage: s.frame - s.remoteFrame,
+		Ack:       s.remoteFrames,
+		Start:     s.acked,
+		SumFrame:  s.summed,
+		Sum:       s.lastSum,
+	}
+	for f := s.acked; f < s.localFrames; f++ {
+		p.Inputs = append(p.Inputs, s.pack(s.inputs[f-s.base]))
+	}
+	data, err := json.Marshal(p)
+	if err == nil {
+		err = s.link.Send(data)
+	}
+	if err != nil && s.err == nil {
+		s.err = fmt.Errorf("rollback: send: %w", err)
+	}
+}
+
+// receive takes the remote's packets and notes the first frame whose
+// guess turned out wrong.
+func (s *Session) receive() {
+	for {
+		data, ok := s.link.Receive()
+		if !ok {
+			return
+		}
+		var p packet
+		if err := json.Unmarshal(data, &p); err != nil {
+			if s.err == nil {
+				s.err = fmt.Errorf("rollback: bad packet: %w", err)
+			}
+			continue
+		}
+		s.acked = min(max(s.acked, p.Ack), s.localFrames)
+		if !s.heard || p.Frame > s.remoteFrame {
+			s.heard = true
+			s.remoteFrame, s.remoteAdvantage = p.Frame, p.Advantage
+		}
+		for k, bits := range p.Inputs {
+			f := p.Start + k
+			if f < s.remoteFrames {
+				continue
+			}
+			if f > s.remoteFrames {
+				break
+			}
+			in := s.ensure(f)
+			for i := range in {
+				if s.Local(i) {
+					continue
+				}
+				x := unpack(bits >> (4 * (i / 2)))
+				if f < s.frame && x != in[i] && (s.rewind < 0 || f < s.rewind) {
+					s.rewind = f
+				}
+				in[i] = x
+			}
+			s.remoteFrames++
+		}
+		if p.SumFrame > 0 {
+			s.compare(p.SumFrame, p.Sum)
+		}
+	}
+}
+
+// pack encodes the local players' input of one frame.
+func (s *Session) pack(in []engine.Input) int {
+	bits := 0
+	for i, x := range in {
+		if s.Local(i) {
+			bits |= packInput(x) << (4 * (i / 2))
+		}
+	}
+	return bits
+}
+
+func packInput(in engine.Input) int {
+	bits := 0
+	for k, held := range []bool{in.Left, in.Right, in.Jump, in.Serve} {
+		if held {
+			bits |= 1 << k
+		}
+	}
+	return bits
+}
+
+func unpack(bits int) engine.Input {
+	return engine.Input{Left: bits&1 != 0, Right: bits&2 != 0, Jump: bits&4 != 0, Serve: bits&8 != 0}
+}
+
+// checksum sums up the game every syncInterval frames once every input
+// before that frame is known, and checks it against the remote's sum.
+func (s *Session) checksum() {
+	done := min(s.remoteFrames, s.frame)
+	for f := s.summed + syncInterval; f <= done; f += syncInterval {
+		st := s.states[f%len(s.states)]
+		if f == s.frame {
+			st = s.g.Snapshot()
+		}
+		s.summed, s.lastSum = f, sum(st)
+		s.sums[f] = s.lastSum
+		if r, ok := s.remoteSums[f]; ok {
+			s.compare(f, r)
+		}
+	}
+}
+
+// compare checks the remote's checksum of frame f against ours, or keeps
+// it until ours is known.
+func (s *Session) compare(f int, remote uint64) {
+	if f <= s.checked {
+		return
+	}
+	own, ok := s.sums[f]
+	if !ok {
+		s.remoteSums[f] = remote
+		return
+	}
+	s.checked = f
+	delete(s.remoteSums, f)
+	if own != remote && s.err == nil {
+		s.err = fmt.Errorf("%w before frame %d", ErrDesync, f)
+	}
+}
+
+func sum(st engine.Snapshot) uint64 {
+	data, _ := json.Marshal(st)
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64()
+}
+
+// trim forgets inputs and checksums that can no longer be needed.
+func (s *Session) trim() {
+	keep := min(s.acked, s.remoteFrames-1, s.frame-len(s.states))
+	if n := keep - s.base; n > 1024 {
+		s.inputs = append([][]engine.Input(nil), s.inputs[n:]...)
+		s.base += n
+	}
+	for f := range s.sums {
+		if f < s.checked {
+			delete(s.sums, f)
+		}
+	}
+	for f := range s.remoteSums {
+		if f < s.checked {
+			delete(s.remoteSums, f)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package rollback
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"terminalvolley/internal/engine"
+	"terminalvolley/internal/netsim"
+)
+
+const tick = time.Second / engine.DefaultTickRate
+
+// newGame is a short 2v2 match, so rematches happen too.
+func newGame() *engine.Game {
+	return engine.New(80, 24, engine.WithTeamSize(2), engine.WithRules(engine.Rules{PointsToWin: 2}))
+}
+
+// player makes up what a peer's players hold, keeping keys down for a
+// while like a person would and serving now and then.
+type player struct {
+	rng *rand.Rand
+	in  []engine.Input
+}
+
+func newPlayer(seed int64) *player {
+	return &player{rng: rand.New(rand.NewSource(seed)), in: make([]engine.Input, 4)}
+}
+
+func (p *player) next() []engine.Input {
+	for i := range p.in {
+		if p.rng.Intn(25) == 0 {
+			p.in[i] = engine.Input{Left: p.rng.Intn(2) == 0, Right: p.rng.Intn(2) == 0, Jump: p.rng.Intn(3) == 0}
+		}
+		p.in[i].Serve = p.rng.Intn(40) == 0
+	}
+	return append([]engine.Input(nil), p.in...)
+}
+
+// pair is two sessions on a simulated network, with the input each
+// session took recorded to replay the match without a network.
+type pair struct {
+	net     *netsim.Network
+	peers   [2]*Session
+	players [2]*player
+	taken   [2][][]engine.Input
+	delay   int
+}
+
+func newPair(c netsim.Conditions, delay int, seed int64) *pair {
+	net := netsim.New(c, seed)
+	a, b := net.Ends()
+	return &pair{
+		net:     net,
+		peers:   [2]*Session{New(newGame(), 0, a, WithInputDelay(delay)), New(newGame(), 1, b, WithInputDelay(delay))},
+		players: [2]*player{newPlayer(seed), newPlayer(seed + 1)},
+		delay:   delay,
+	}
+}
+
+// run steps both peers for n ticks, or only peer 0 while the other has
+// not started yet.
+func (p *pair) run(t *testing.T, n int, started [2]bool) {
+	t.Helper()
+	for k := 0; k < n; k++ {
+		p.net.Advance(tick)
+		for i, s := range p.peers {
+			if !started[i] {
+				continue
+			}
+			in := p.players[i].next()
+			if s.Step(in) {
+				p.taken[i] = append(p.taken[i], in)
+			}
+			if err := s.Err(); err != nil {
+				t.Fatalf("peer %d: %v", i, err)
+			}
+		}
+	}
+}
+
+// stateBefore returns s's game before frame f, which must be recent.
+func stateBefore(s *Session, f int) engine.Snapshot {
+	if f == s.frame {
+		return s.g.Snapshot()
+	}
+	return s.states[f%len(s.states)]
+}
+
+// replay plays the first n frames of the recorded input on one game and
+// counts the matches finished.
+func (p *pair) replay(n int) (engine.Snapshot, int) {
+	g := newGame()
+	s := New(g, 0, nil)
+	matches := 0
+	for f := 0; f < n; f++ {
+		in := make([]engine.Input, g.NumPlayers())
+		for i := range in {
+			if k := f - p.delay; k >= 0 {
+				in[i] = p.taken[g.Player(i).Team][k][i]
+			}
+		}
+		s.remoteFrames = f + 1
+		s.inputs = append(s.inputs, in)
+		over := g.Over()
+		s.simulate(f)
+		if !over && g.Over() {
+			matches++
+		}
+	}
+	return g.Snapshot(), matches
+}
+
+// settle runs until both peers know all input of a common frame and
+// checks that both games are the one the recorded input gives. It returns
+// how many matches that input finished.
+func (p *pair) settle(t *testing.T) int {
+	t.Helper()
+	p.run(t, engine.DefaultTickRate, [2]bool{true, true})
+	f := min(p.peers[0].Confirmed(), p.peers[1].Confirmed(), p.peers[0].Frame(), p.peers[1].Frame())
+	a, b := stateBefore(p.peers[0], f), stateBefore(p.peers[1], f)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("peers differ before frame %d:\n%+v\n%+v", f, a, b)
+	}
+	want, matches := p.replay(f)
+	if !reflect.DeepEqual(a, want) {
+		t.Fatalf("peers differ from the replay before frame %d:\n got %+v\nwant %+v", f, a, want)
+	}
+	return matches
+}
+
+func TestSession_BadNetworkStaysInSync(t *testing.T) {
+	for _, c := range []netsim.Conditions{
+		{Latency: 40 * time.Millisecond, Jitter: 15 * time.Millisecond},
+		{Latency: 25 * time.Millisecond, Jitter: 5 * time.Millisecond, Loss: 0.2},
+		{Latency: 120 * time.Millisecond, Jitter: 60 * time.Millisecond, Loss: 0.05},
+	} {
+		p := newPair(c, 2, 1)
+		p.run(t, 30*engine.DefaultTickRate, [2]bool{true, true})
+		if matches := p.settle(t); matches == 0 {
+			t.Errorf("%+v: no match was finished", c)
+		}
+		for i, s := range p.peers {
+			st := s.Stats()
+			if st.Rollbacks == 0 || st.Longest < 2 {
+				t.Errorf("%+v: peer %d never rolled back: %+v", c, i, st)
+			}
+			if s.Frame() < 25*engine.DefaultTickRate {
+				t.Errorf("%+v: peer %d only reached frame %d", c, i, s.Frame())
+			}
+		}
+	}
+}
+
+func TestSession_InputDelayHidesLatency(t *testing.T) {
+	c := netsim.Conditions{Latency: 20 * time.Millisecond}
+	latency := int(c.Latency / tick)
+	for _, delay := range []int{0, latency + 1} {
+		p := newPair(c, delay, 2)
+		p.run(t, 10*engine.DefaultTickRate, [2]bool{true, true})
+		p.settle(t)
+		rollbacks := p.peers[0].Stats().Rollbacks + p.peers[1].Stats().Rollbacks
+		if delay > latency && rollbacks != 0 {
+			t.Errorf("delay %d: %d rollbacks, want none", delay, rollbacks)
+		}
+		if delay == 0 && rollbacks == 0 {
+			t.Errorf("delay 0: expected rollbacks")
+		}
+	}
+}
+
+func TestSession_StallsWithoutRemote(t *testing.T) {
+	p := newPair(netsim.Conditions{}, 0, 3)
+	p.run(t, 2*DefaultMaxRollback, [2]bool{true, false})
+	s := p.peers[0]
+	if s.Frame() != DefaultMaxRollback {
+		t.Fatalf("ran %d frames ahead of a silent peer, want %d", s.Frame(), DefaultMaxRollback)
+	}
+	if st := s.Stats(); st.Stalls != DefaultMaxRollback {
+		t.Fatalf("stalls = %d, want %d", st.Stalls, DefaultMaxRollback)
+	}
+	// Once the other peer shows up the two carry on together.
+	p.run(t, 5*engine.DefaultTickRate, [2]bool{true, true})
+	p.settle(t)
+}
+
+func TestSession_LateStarterCatchesUp(t *testing.T) {
+	p := newPair(netsim.Conditions{Latency: 10 * time.Millisecond}, 1, 4)
+	p.run(t, 40, [2]bool{true, false})
+	p.run(t, 10*engine.DefaultTickRate, [2]bool{true, true})
+	a, b := p.peers[0], p.peers[1]
+	if d := a.Frame() - b.Frame(); d < -2 || d > 2 {
+		t.Fatalf("peers are %d frames apart", d)
+	}
+	if a.Stats().Waits == 0 {
+		t.Fatalf("expected the early peer to wait for the late one")
+	}
+	p.settle(t)
+}
+
+func TestSession_DetectsDesync(t *testing.T) {
+	net := netsim.New(netsim.Conditions{Latency: 10 * time.Millisecond}, 5)
+	a, b := net.Ends()
+	phys := engine.DefaultPhysics()
+	phys.BallGravity *= 1.01
+	peers := [2]*Session{
+		New(engine.New(80, 24), 0, a),
+		New(engine.New(80, 24, engine.WithPhysics(phys)), 1, b),
+	}
+	players := [2]*player{newPlayer(1), newPlayer(2)}
+	for k := 0; k < 20*engine.DefaultTickRate; k++ {
+		net.Advance(tick)
+		for i, s := range peers {
+			s.Step(players[i].next())
+			if err := s.Err(); err != nil {
+				if !errors.Is(err, ErrDesync) {
+					t.Fatalf("peer %d: %v, want a desync", i, err)
+				}
+				return
+			}
+		}
+	}
+	t.Fatal("the diverging games were not noticed")
+}
+
+func TestPackRoundTrips(t *testing.T) {
+	s := New(newGame(), 1, nil)
+	in := []engine.Input{{}, {Left: true, Serve: true}, {Jump: true}, {Right: true, Jump: true}}
+	bits := s.pack(in)
+	for i, want := range in {
+		if i%2 == 0 {
+			continue
+		}
+		if got := unpack(bits >> (4 * (i / 2))); got != want {
+			t.Errorf("player %d: got %+v, want %+v", i, got, want)
+		}
+	}
+}
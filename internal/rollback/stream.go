@@ -0,0 +1,68 @@
+package rollback
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Stream is a Link over a byte stream such as a TCP connection, one
+// packet per line. Packets are never lost but may queue up behind each
+// other.
+type Stream struct {
+	w    io.Writer
+	in   chan []byte
+	done chan struct{} // closed when the stream has ended
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewStream starts reading packets from rw.
+func NewStream(rw io.ReadWriter) *Stream {
+	s := &Stream{w: rw, in: make(chan []byte, 256), done: make(chan struct{})}
+	go s.read(rw)
+	return s
+}
+
+// Send implements Link.
+func (s *Stream) Send(packet []byte) error {
+	_, err := s.w.Write(append(packet, '\n'))
+	return err
+}
+
+// Receive implements Link.
+func (s *Stream) Receive() ([]byte, bool) {
+	select {
+	case p := <-s.in:
+		return p, true
+	default:
+		return nil, false
+	}
+}
+
+// Done is closed once the other end has hung up.
+func (s *Stream) Done() <-chan struct{} { return s.done }
+
+// Err returns why the stream ended, or nil while it is open.
+func (s *Stream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Stream) read(r io.Reader) {
+	defer close(s.done)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		s.in <- append([]byte(nil), sc.Bytes()...)
+	}
+	err := sc.Err()
+	if err == nil {
+		err = errors.New("hung up")
+	}
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
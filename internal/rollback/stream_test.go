@@ -0,0 +1,70 @@
+package rollback
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStream_PlaysOverTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			t.Error(err)
+		}
+		accepted <- c
+	}()
+	c1, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c1.Close()
+	c0 := <-accepted
+	if c0 == nil {
+		t.FailNow()
+	}
+	defer c0.Close()
+
+	links := [2]*Stream{NewStream(c0), NewStream(c1)}
+	var peers [2]*Session
+	for i := range peers {
+		peers[i] = New(newGame(), i, links[i], WithInputDelay(2))
+	}
+	players := [2]*player{newPlayer(1), newPlayer(2)}
+	for k := 0; k < 1500; k++ {
+		for i, s := range peers {
+			s.Step(players[i].next())
+			if err := s.Err(); err != nil {
+				t.Fatalf("peer %d: %v", i, err)
+			}
+		}
+		if k%5 == 0 {
+			time.Sleep(50 * time.Microsecond)
+		}
+	}
+	a, b := peers[0], peers[1]
+	if a.Frame() < 750 || b.Frame() < 750 {
+		t.Fatalf("peers only reached frames %d and %d", a.Frame(), b.Frame())
+	}
+	f := min(a.Confirmed(), b.Confirmed(), a.Frame(), b.Frame())
+	if sa, sb := stateBefore(a, f), stateBefore(b, f); !reflect.DeepEqual(sa, sb) {
+		t.Fatalf("peers differ before frame %d:\n%+v\n%+v", f, sa, sb)
+	}
+
+	c1.Close()
+	select {
+	case <-links[0].Done():
+	case <-time.After(time.Second):
+		t.Fatal("the stream did not notice the hang-up")
+	}
+	if links[0].Err() == nil {
+		t.Fatal("expected an error once the other end hung up")
+	}
+}
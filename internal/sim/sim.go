@@ -0,0 +1,212 @@
+// Package sim plays matches between two contenders without a terminal, as
+// fast as the machine allows, and sums up how they did.
+package sim
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"terminalvolley/internal/engine"
+)
+
+// Contender is one of the two sides of a simulation.
+type Contender struct {
+	Name string
+	// New returns the controller for player i in match m.
+	New func(m, i int) (engine.Controller, error)
+}
+
+// Config describes a simulation. The contenders swap teams every match so
+// neither always serves first.
+type Config struct {
+	Contenders [2]Contender
+	Matches    int
+	// NewGame returns a fresh game for each match.
+	NewGame func() *engine.Game
+	// MaxTicks ends a match that has not been decided after this many
+	// ticks; it then counts as unfinished.
+	MaxTicks int
+	// Workers is how many matches run at once; 0 means one per CPU.
+	Workers int
+}
+
+// Report sums up a simulation.
+type Report struct {
+	Matches    int      `json:"matches"`
+	Unfinished int      `json:"unfinished"`
+	Contenders [2]Stats `json:"contenders"`
+	Rallies    int      `json:"rallies"`
+	// AvgRallyHits is the mean number of blob contacts per rally and
+	// AvgRallySeconds its mean duration in game time.
+	AvgRallyHits    float64 `json:"avgRallyHits"`
+	AvgRallySeconds float64 `json:"avgRallySeconds"`
+	GameSeconds     float64 `json:"gameSeconds"`
+}
+
+// Stats is how one contender did.
+type Stats struct {
+	Name    string  `json:"name"`
+	Wins    int     `json:"wins"`
+	WinRate float64 `json:"winRate"`
+	// Points counts rallies won that scored; Serves counts rallies
+	// served, and PointsPerServe how many points each serve earned.
+	Points         int     `json:"points"`
+	Serves         int     `json:"serves"`
+	PointsPerServe float64 `json:"pointsPerServe"`
+}
+
+// result is what one match contributes to the report, per contender.
+type result struct {
+	winner      int // contender, -1 if unfinished
+	points      [2]int
+	serves      [2]int
+	servePoints [2]int
+	rallies     int
+	hits        int
+	rallyTicks  int
+	ticks       int
+	dt          float64
+}
+
+// Run plays the matches and reports the outcome.
+func Run(cfg Config) (Report, error) {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	results := make([]result, cfg.Matches)
+	errs := make([]error, cfg.Matches)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range jobs {
+				results[m], errs[m] = play(cfg, m)
+			}
+		}()
+	}
+	for m := 0; m < cfg.Matches; m++ {
+		jobs <- m
+	}
+	close(jobs)
+	wg.Wait()
+
+	for m, err := range errs {
+		if err != nil {
+			return Report{}, fmt.Errorf("match %d: %w", m+1, err)
+		}
+	}
+	return summarize(cfg, results), nil
+}
+
+// play runs match m. Contender 0 plays team 1 in even matches and team 2
+// in odd ones.
+func play(cfg Config, m int) (result, error) {
+	g := cfg.NewGame()
+	contender := func(team int) int { return team ^ m%2 }
+
+	ctrls := make([]engine.Controller, g.NumPlayers())
+	defer func() {
+		for _, c := range ctrls {
+			if cl, ok := c.(io.Closer); ok {
+				cl.Close()
+			}
+		}
+	}()
+	for i := range ctrls {
+		c, err := cfg.Contenders[contender(g.Player(i).Team)].New(m, i)
+		if err != nil {
+			return result{}, err
+		}
+		ctrls[i] = c
+	}
+
+	r := result{winner: -1, dt: g.DT()}
+	rallyStart := 0
+	for !g.Over() && (cfg.MaxTicks <= 0 || r.ticks < cfg.MaxTicks) {
+		// Read the state before the controllers act: a serve request
+		// takes effect at once.
+		waiting := g.WaitingServe()
+		server := contender(g.Player(g.Serving()).Team)
+		points := teamPoints(g)
+		for i, c := range ctrls {
+			g.SetInput(i, c.Control(g, i))
+		}
+		g.Step()
+		r.ticks++
+
+		if waiting && !g.WaitingServe() {
+			r.serves[server]++
+			rallyStart = r.ticks - 1
+		}
+		if !waiting && (g.WaitingServe() || g.Over()) {
+			r.rallies++
+			r.rallyTicks += r.ticks - rallyStart
+			for team, n := range teamPoints(g) {
+				if n > points[team] {
+					c := contender(team)
+					r.points[c]++
+					if c == server {
+						r.servePoints[c]++
+					}
+				}
+			}
+		}
+	}
+	h1, h2 := g.Hits()
+	r.hits = h1 + h2
+	if g.Over() {
+		r.winner = contender(g.Winner())
+	}
+	return r, nil
+}
+
+// teamPoints is the points each team has scored.
+func teamPoints(g *engine.Game) [2]int {
+	var p [2]int
+	p[0], p[1] = g.Points()
+	return p
+}
+
+func summarize(cfg Config, results []result) Report {
+	rep := Report{Matches: len(results)}
+	var hits, rallyTicks float64
+	for c := range rep.Contenders {
+		rep.Contenders[c].Name = cfg.Contenders[c].Name
+	}
+	servePoints := [2]int{}
+	for _, r := range results {
+		if r.winner < 0 {
+			rep.Unfinished++
+		} else {
+			rep.Contenders[r.winner].Wins++
+		}
+		for c := range rep.Contenders {
+			rep.Contenders[c].Points += r.points[c]
+			rep.Contenders[c].Serves += r.serves[c]
+			servePoints[c] += r.servePoints[c]
+		}
+		rep.Rallies += r.rallies
+		hits += float64(r.hits)
+		rallyTicks += float64(r.rallyTicks) * r.dt
+		rep.GameSeconds += float64(r.ticks) * r.dt
+	}
+	for c := range rep.Contenders {
+		s := &rep.Contenders[c]
+		if rep.Matches > 0 {
+			s.WinRate = float64(s.Wins) / float64(rep.Matches)
+		}
+		if s.Serves > 0 {
+			s.PointsPerServe = float64(servePoints[c]) / float64(s.Serves)
+		}
+	}
+	if rep.Rallies > 0 {
+		rep.AvgRallyHits = hits / float64(rep.Rallies)
+		rep.AvgRallySeconds = rallyTicks / float64(rep.Rallies)
+	}
+	return rep
+}
@@ -0,0 +1,102 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+
+	"terminalvolley/internal/ai"
+	"terminalvolley/internal/engine"
+)
+
+func cpu(name string) Contender {
+	d, _ := ai.Level(name)
+	return Contender{Name: name, New: func(m, i int) (engine.Controller, error) {
+		return ai.NewCPU(ai.WithDifficulty(d), ai.WithSeed(int64(m*10+i))), nil
+	}}
+}
+
+type idle struct{}
+
+func (idle) Control(*engine.Game, int) engine.Input { return engine.Input{} }
+
+func shortMatch() *engine.Game {
+	return engine.New(80, 24, engine.WithRules(engine.Rules{PointsToWin: 3}))
+}
+
+func TestRun_ExpertBeatsBeginner(t *testing.T) {
+	rep, err := Run(Config{
+		Contenders: [2]Contender{cpu("beginner"), cpu("expert")},
+		Matches:    4,
+		NewGame:    shortMatch,
+		MaxTicks:   200 * 600,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	beginner, expert := rep.Contenders[0], rep.Contenders[1]
+	if rep.Unfinished != 0 || beginner.Wins+expert.Wins != 4 {
+		t.Fatalf("expected 4 decided matches, got %+v", rep)
+	}
+	if expert.WinRate <= beginner.WinRate || expert.Name != "expert" {
+		t.Fatalf("expected the expert to win more often, got %+v", rep)
+	}
+	if expert.Points < 3*expert.Wins || beginner.Serves+expert.Serves != rep.Rallies {
+		t.Fatalf("unexpected points and serves %+v", rep)
+	}
+	if rep.Rallies != beginner.Points+expert.Points || rep.AvgRallyHits < 1 || rep.AvgRallySeconds <= 0 {
+		t.Fatalf("unexpected rally stats %+v", rep)
+	}
+}
+
+func TestRun_GivesUpOnUndecidedMatches(t *testing.T) {
+	stand := Contender{Name: "idle", New: func(m, i int) (engine.Controller, error) { return idle{}, nil }}
+	rep, err := Run(Config{
+		Contenders: [2]Contender{stand, stand},
+		Matches:    3,
+		NewGame:    shortMatch,
+		MaxTicks:   100,
+		Workers:    1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Unfinished != 3 || rep.Rallies != 0 || rep.GameSeconds != 1.5 {
+		t.Fatalf("expected three unfinished matches of 100 ticks, got %+v", rep)
+	}
+}
+
+func TestRun_ControllerError(t *testing.T) {
+	broken := Contender{Name: "broken", New: func(m, i int) (engine.Controller, error) { return nil, errors.New("no bot") }}
+	if _, err := Run(Config{Contenders: [2]Contender{cpu("easy"), broken}, Matches: 1, NewGame: shortMatch}); err == nil {
+		t.Fatalf("expected the controller error")
+	}
+}
+
+func TestPlay_CountsEveryPointAndTouch(t *testing.T) {
+	// Several sets and a touch limit: points that end a set and touches
+	// that fault both count.
+	var g *engine.Game
+	cfg := Config{
+		Contenders: [2]Contender{cpu("easy"), cpu("expert")},
+		NewGame: func() *engine.Game {
+			g = engine.New(80, 24, engine.WithRules(engine.Rules{PointsToWin: 2, Sets: 3, MaxTouches: 3}))
+			return g
+		},
+		MaxTicks: 200 * 600,
+	}
+	// In odd matches contender 0 plays team 2.
+	r, err := play(cfg, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Over() {
+		t.Fatalf("expected the match to finish")
+	}
+	p1, p2 := g.Points()
+	if r.points != [2]int{p2, p1} || r.rallies != p1+p2 {
+		t.Fatalf("points %v over %d rallies, want %v over %d", r.points, r.rallies, [2]int{p2, p1}, p1+p2)
+	}
+	if h1, h2 := g.Hits(); r.hits != h1+h2 {
+		t.Fatalf("hits = %d, want %d", r.hits, h1+h2)
+	}
+}